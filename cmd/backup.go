@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/progress"
+	"github.com/benjdewan/pachelbel/runner"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup <name|id>...",
+	Short: "Take on-demand backups of compose deployments",
+	Long: `pachelbel backup reads a list of deployment names and/or IDs as
+arguments, and starts an on-demand backup of each of them.
+
+By default this command does not wait for the backup recipes to finish, but
+you can use the '--wait' flag to force it to.`,
+	Run: runBackup,
+}
+
+// backupTarget is a deployment to back up
+type backupTarget struct {
+	id             string
+	name           string
+	deploymentType string
+	timeout        float64
+}
+
+func (b backupTarget) GetID() string {
+	return b.id
+}
+
+func (b backupTarget) GetName() string {
+	return b.name
+}
+
+func (b backupTarget) GetType() string {
+	return b.deploymentType
+}
+
+func (b backupTarget) GetTimeout() float64 {
+	return b.timeout
+}
+
+func runBackup(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		log.Fatal("The 'backup' command requires at least one deployment name or ID as input")
+	}
+
+	cxn, err := openConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		if closeErr := cxn.Close(); closeErr != nil {
+			panic(closeErr)
+		}
+	}()
+
+	runners, err := backupRunners(cxn, args)
+	if err != nil {
+		fatal(err)
+	}
+
+	display, err := progress.NewDisplay(viper.GetString("progress"), os.Stdout)
+	if err != nil {
+		fatal(err)
+	}
+	ctl := runner.NewController(cxn, display, viper.GetBool("dry-run"))
+	runErr := ctl.Run(runners)
+	printWarnings(cxn.TakeWarnings())
+	if err := ctl.Report().WriteSummary(os.Stdout); err != nil {
+		fatal(err)
+	}
+	if runErr != nil {
+		fatal(runErr)
+	}
+}
+
+func backupRunners(cxn *connection.Connection, args []string) ([]runner.Runner, error) {
+	timeout := float64(viper.GetInt("backup-timeout"))
+	if !viper.GetBool("backup-wait") {
+		timeout = 0
+	}
+
+	runners := []runner.Runner{}
+	for _, arg := range args {
+		existing, err := cxn.ExistingDeployment(arg)
+		if err != nil {
+			return runners, err
+		}
+		runners = append(runners, runner.Runner{
+			Target: backupTarget{
+				id:             existing.ID,
+				name:           existing.Name,
+				deploymentType: existing.Type,
+				timeout:        timeout,
+			},
+			Action: runner.ActionBackup,
+			Run:    runner.Backup,
+		})
+	}
+	return runners, nil
+}
+
+func init() {
+	backupCmd.Flags().BoolP("wait", "w", false,
+		`Wait for backup recipes to complete before
+			returning`)
+	if err := viper.BindPFlag("backup-wait", backupCmd.Flags().Lookup("wait")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	backupCmd.Flags().IntP("timeout", "t", 900,
+		`The amount of time to wait, in seconds, for
+			backup recipes to complete.
+
+			Ignored if '--wait' is not set`)
+	if err := viper.BindPFlag("backup-timeout", backupCmd.Flags().Lookup("timeout")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	RootCmd.AddCommand(backupCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	compose "github.com/benjdewan/gocomposeapi"
+	"github.com/benjdewan/pachelbel/errorqueue"
+	"github.com/spf13/cobra"
+)
+
+var backupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "List the backups of compose deployments",
+}
+
+var backupsListCmd = &cobra.Command{
+	Use:   "list <name|id>...",
+	Short: "List the backups of compose deployments",
+	Long: `pachelbel backups list reads a list of deployment names and/or IDs
+as arguments, and prints the ID, type, status and creation time of every
+backup of each of them.`,
+	Run: runBackupsList,
+}
+
+// deploymentBackups are the backups of a single deployment
+type deploymentBackups struct {
+	name    string
+	backups []compose.Backup
+}
+
+func runBackupsList(cmd *cobra.Command, args []string) {
+	all, err := listBackups(args)
+	if printErr := printBackups(all); printErr != nil {
+		fatal(printErr)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// listBackups returns the backups of every deployment that could be listed,
+// and an error for the ones that could not
+func listBackups(args []string) ([]deploymentBackups, error) {
+	if len(args) == 0 {
+		log.Fatal("At least one deployment name or ID is required as input")
+	}
+
+	cxn, err := openConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		if closeErr := cxn.Close(); closeErr != nil {
+			panic(closeErr)
+		}
+	}()
+
+	q := errorqueue.New()
+	all := []deploymentBackups{}
+	for _, arg := range args {
+		existing, err := cxn.ExistingDeployment(arg)
+		if err != nil {
+			q.Enqueue(err)
+			continue
+		}
+		backups, err := cxn.Backups(existing.ID, existing.Name)
+		if err != nil {
+			q.Enqueue(err)
+			continue
+		}
+		all = append(all, deploymentBackups{name: existing.Name, backups: backups})
+	}
+	return all, q.Flush()
+}
+
+func printBackups(all []deploymentBackups) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "DEPLOYMENT\tBACKUP\tTYPE\tSTATUS\tCREATED"); err != nil {
+		return err
+	}
+	for _, deployment := range all {
+		for _, backup := range deployment.backups {
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", deployment.name, backup.ID,
+				backup.Type, backup.Status, backup.CreatedAt.UTC().Format(time.RFC3339)); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+func init() {
+	backupsCmd.AddCommand(backupsListCmd)
+	RootCmd.AddCommand(backupsCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/benjdewan/pachelbel/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var decryptOutputCmd = &cobra.Command{
+	Use:   "decrypt-output",
+	Short: "Decrypt connection information written by pachelbel provision",
+	Long: `pachelbel decrypt-output reads a connection information file that was
+encrypted by 'pachelbel provision' and writes the decrypted YAML to stdout, or
+to the file specified using the --output flag.
+
+The file can be decrypted with the passphrase it was encrypted with, or with
+the private key of any one of the recipients it was encrypted for.`,
+	Run: runDecryptOutput,
+}
+
+func runDecryptOutput(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		log.Fatal("The 'decrypt-output' command requires exactly one encrypted file as input")
+	}
+
+	e, err := buildEnvelope(viper.GetString("decrypt-passphrase-env"),
+		viper.GetString("decrypt-passphrase-file"),
+		viper.GetString("decrypt-identity-file"), []string{})
+	if err != nil {
+		fatal(err)
+	} else if e == nil || !e.CanOpen() {
+		log.Fatal("A passphrase or private key is required to decrypt output")
+	}
+
+	dst := output.Destination{
+		File: viper.GetString("decrypt-output"),
+		Mode: output.DefaultMode,
+	}
+	if err := output.Decrypt(args[0], e, dst); err != nil {
+		fatal(err)
+	}
+}
+
+func init() {
+	decryptOutputCmd.Flags().String("passphrase-env", "",
+		`The name of an environment variable containing
+				 the passphrase the output was encrypted with`)
+	if err := viper.BindPFlag("decrypt-passphrase-env", decryptOutputCmd.Flags().Lookup("passphrase-env")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	decryptOutputCmd.Flags().String("passphrase-file", "",
+		`A file containing the passphrase the output
+				 was encrypted with`)
+	if err := viper.BindPFlag("decrypt-passphrase-file", decryptOutputCmd.Flags().Lookup("passphrase-file")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	decryptOutputCmd.Flags().String("identity-file", "",
+		`A file containing a base64 encoded x25519
+				 private key matching one of the recipients
+				 the output was encrypted for`)
+	if err := viper.BindPFlag("decrypt-identity-file", decryptOutputCmd.Flags().Lookup("identity-file")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	decryptOutputCmd.Flags().StringP("output", "o", "-",
+		`The file to write the decrypted output to.
+				 By default it is written to stdout.`)
+	if err := viper.BindPFlag("decrypt-output", decryptOutputCmd.Flags().Lookup("output")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	RootCmd.AddCommand(decryptOutputCmd)
+}
@@ -1,61 +1,287 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"io"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	compose "github.com/benjdewan/gocomposeapi"
+	"github.com/benjdewan/pachelbel/config"
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/progress"
+	"github.com/benjdewan/pachelbel/runner"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var deprovisionCmd = &cobra.Command{
-	Use:   "deprovision",
+	Use:   "deprovision [name|id|pattern|config path]...",
 	Short: "Idempotent deprovisioner of compose deployments",
-	Long: `pachelbel deprovision reads a list of deployment names as
-arguments. For each deployment pachelbel will, check if the deployment exists,
-and if it does sends a deprovisioning request to the Compose API. If a
-specified deployment does not exist or has already been deleted it is ignored.`,
+	Long: `pachelbel deprovision selects deployments to deprovision by:
+
+  * the exact names or IDs provided as arguments,
+  * shell patterns like 'pg-staging-*' provided as arguments,
+  * regular expressions provided with '--regex',
+  * every deployment declared in YAML configuration files, or directories
+    containing them, provided as arguments.
+
+The selection can be narrowed with '--type', '--notes' and '--cluster'. They
+only select from every deployment in the account if '--all' is set.
+Configuration paths only select the deployments they declare, so
+configuration declaring none selects nothing. Other arguments, including files
+without a '.yml' or '.yaml' extension, are read as names, IDs or patterns.
+Names, IDs and declared deployments that do not exist are ignored.
+
+The selected deployments are listed and pachelbel asks for confirmation
+before sending deprovisioning requests to the Compose API. Use '--yes' to
+skip the confirmation when running non-interactively.
+
+Use '--grace' to take a final backup of each deployment and mark it in its
+notes as pending deletion instead. 'pachelbel reap' deprovisions deployments
+whose grace period has expired, and 'pachelbel undelete' removes the mark.`,
 	Run: runDeprovision,
 }
 
+// deprovisionTarget is a deployment to deprovision
+type deprovisionTarget struct {
+	id             string
+	name           string
+	deploymentType string
+	timeout        float64
+	grace          time.Duration
+}
+
+func (d deprovisionTarget) GetID() string {
+	return d.id
+}
+
+func (d deprovisionTarget) GetName() string {
+	return d.name
+}
+
+func (d deprovisionTarget) GetType() string {
+	return d.deploymentType
+}
+
+func (d deprovisionTarget) GetTimeout() float64 {
+	return d.timeout
+}
+
+func (d deprovisionTarget) GetGracePeriod() time.Duration {
+	return d.grace
+}
+
 func runDeprovision(cmd *cobra.Command, args []string) {
-	assertCanDeprovision(args)
-	file, err := writeDeprovisionFile(args)
+	grace, err := gracePeriod()
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
-	provisionCmd.Run(cmd, []string{file})
-	if err = os.Remove(file); err != nil {
-		log.Fatal(err)
+	selector, err := deprovisionSelector(args)
+	if err != nil {
+		fatal(err)
+	} else if selector.Empty() {
+		fmt.Println("Nothing to do")
+		return
+	}
+
+	cxn, err := openConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		if closeErr := cxn.Close(); closeErr != nil {
+			panic(closeErr)
+		}
+	}()
+
+	dryRun := viper.GetBool("dry-run")
+	if !dryRun {
+		if err = resumePending(cxn); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+	deployments, err := cxn.Deployments()
+	if err != nil {
+		fatal(err)
+	}
+	selected := selector.Select(deployments)
+	if len(selected) == 0 {
+		fmt.Println("Nothing to do")
+		return
+	}
+	if err = printSelected(os.Stdout, selected); err != nil {
+		fatal(err)
+	}
+	if !dryRun && !viper.GetBool("yes") {
+		if err = confirmDeprovision(len(selected), grace); err != nil {
+			fatal(err)
+		}
+	}
+
+	display, err := progress.NewDisplay(viper.GetString("progress"), os.Stdout)
+	if err != nil {
+		fatal(err)
+	}
+	ctl := runner.NewController(cxn, display, dryRun)
+	runErr := ctl.Run(deprovisionRunners(selected, grace))
+	printWarnings(cxn.TakeWarnings())
+	if err := ctl.Report().WriteSummary(os.Stdout); err != nil {
+		fatal(err)
+	}
+	if runErr != nil {
+		fatal(runErr)
 	}
 }
 
-const deprovisionTemplate = `config_version: 2
-object_type: deprovision
-name: %s
-timeout: %d`
+func gracePeriod() (time.Duration, error) {
+	raw := viper.GetString("grace")
+	if len(raw) == 0 {
+		return 0, nil
+	}
+	grace, err := parseDuration("--grace", raw)
+	if err == nil && grace == 0 {
+		return 0, errors.New("--grace must be longer than 0")
+	}
+	return grace, err
+}
 
-func writeDeprovisionFile(args []string) (string, error) {
-	timeout := viper.GetInt("timeout")
-	if !viper.GetBool("wait") {
-		timeout = 0
+// parseDuration parses a duration for the named flag, accepting a 'd'
+// suffix for days in addition to the units time.ParseDuration understands
+func parseDuration(flag, raw string) (time.Duration, error) {
+	if strings.HasSuffix(raw, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(raw, "d")); err == nil && days >= 0 {
+			return time.Duration(days) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(raw); err == nil && d >= 0 {
+		return d, nil
 	}
-	objs := []string{}
+	return 0, fmt.Errorf("'%s' is not a valid duration for %s, e.g. '7d' or '36h'", raw, flag)
+}
+
+func deprovisionSelector(args []string) (connection.Selector, error) {
+	selector := connection.Selector{
+		Types:            viper.GetStringSlice("deprovision-type"),
+		Clusters:         viper.GetStringSlice("deprovision-cluster"),
+		DeclaredClusters: make(map[string]string),
+		All:              viper.GetBool("deprovision-all"),
+	}
+
+	configPaths := []string{}
 	for _, arg := range args {
-		objs = append(objs, fmt.Sprintf(deprovisionTemplate, arg, timeout))
+		if config.IsConfigPath(arg) {
+			configPaths = append(configPaths, arg)
+		} else if strings.ContainsAny(arg, "*?[") {
+			selector.Globs = append(selector.Globs, arg)
+		} else {
+			selector.Names = append(selector.Names, arg)
+		}
 	}
-	handle, err := ioutil.TempFile("", "deprovision")
+	declared, err := config.Declared(configPaths)
 	if err != nil {
-		return "", err
+		return selector, err
 	}
-	_, err = handle.WriteString(strings.Join(objs, "\n---\n"))
-	if err != nil {
-		return "", err
+	selector.Configured = len(configPaths) > 0
+	for _, d := range declared {
+		selector.Declared = append(selector.Declared, d.Name)
+		if len(d.Cluster) > 0 {
+			selector.DeclaredClusters[d.Name] = d.Cluster
+		}
 	}
-	return handle.Name(), handle.Close()
+	if len(selector.Clusters) > 0 && len(configPaths) == 0 {
+		return selector, errors.New("--cluster can only select deployments declared in configuration, because the Compose API does not report which cluster a deployment runs in")
+	}
+
+	for _, raw := range viper.GetStringSlice("deprovision-regex") {
+		pattern, err := regexp.Compile(raw)
+		if err != nil {
+			return selector, fmt.Errorf("'%s' is not a valid regular expression for --regex: %v", raw, err)
+		}
+		selector.Patterns = append(selector.Patterns, pattern)
+	}
+	if raw := viper.GetString("deprovision-notes"); len(raw) > 0 {
+		if selector.Notes, err = regexp.Compile(raw); err != nil {
+			return selector, fmt.Errorf("'%s' is not a valid regular expression for --notes: %v", raw, err)
+		}
+	}
+	if selector.Filtered() && !selector.All && len(args) == 0 && len(selector.Patterns) == 0 {
+		return selector, errors.New("--type and --notes only narrow a selection. Use --all to select from every deployment in the account")
+	}
+	return selector, nil
+}
+
+func printSelected(w io.Writer, deployments []compose.Deployment) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "NAME\tID\tTYPE\tVERSION"); err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", deployment.Name, deployment.ID,
+			deployment.Type, deployment.Version); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// confirmDeprovision asks for confirmation on stdin, and returns an error
+// unless it is given
+func confirmDeprovision(count int, grace time.Duration) error {
+	if !progress.IsTerminal(os.Stdin) {
+		return errors.New("Refusing to deprovision without confirmation. Use --yes when running non-interactively")
+	}
+	if grace > 0 {
+		fmt.Printf("Back up these %d deployment(s) and deprovision them after %v? [y/N]: ", count, grace)
+	} else {
+		fmt.Printf("Deprovision these %d deployment(s)? [y/N]: ", count)
+	}
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errors.New("Deprovisioning cancelled")
+	}
+}
+
+func deprovisionRunners(deployments []compose.Deployment, grace time.Duration) []runner.Runner {
+	timeout := float64(viper.GetInt("timeout"))
+	if !viper.GetBool("wait") {
+		timeout = 0
+	}
+	action, run := runner.ActionDeprovision, runner.RunFunc(runner.Deprovision)
+	if grace > 0 {
+		action, run = runner.ActionScheduleDeprovision, runner.ScheduleDeprovision
+	}
+	return targetRunners(deployments, timeout, grace, action, run)
+}
+
+func targetRunners(deployments []compose.Deployment, timeout float64, grace time.Duration, action string, run runner.RunFunc) []runner.Runner {
+	runners := []runner.Runner{}
+	for _, deployment := range deployments {
+		runners = append(runners, runner.Runner{
+			Target: deprovisionTarget{
+				id:             deployment.ID,
+				name:           deployment.Name,
+				deploymentType: deployment.Type,
+				timeout:        timeout,
+				grace:          grace,
+			},
+			Action: action,
+			Run:    run,
+		})
+	}
+	return runners
 }
 
 func init() {
@@ -75,13 +301,63 @@ func init() {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	addDeprovisionSelectorFlags()
+	deprovisionCmd.Flags().String("grace", "",
+		`Instead of deprovisioning the selected
+			deployments, take a final backup of each and
+			mark it to be deprovisioned by 'pachelbel reap'
+			after this long, e.g. '72h' or '3d'`)
+	if err := viper.BindPFlag("grace", deprovisionCmd.Flags().Lookup("grace")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	deprovisionCmd.Flags().BoolP("yes", "y", false,
+		`Deprovision the selected deployments without
+			asking for confirmation`)
+	if err := viper.BindPFlag("yes", deprovisionCmd.Flags().Lookup("yes")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	RootCmd.AddCommand(deprovisionCmd)
 }
 
-func assertCanDeprovision(args []string) {
-	if len(args) == 0 {
-		fmt.Println("Nothing to do")
-		os.Exit(0)
+func addDeprovisionSelectorFlags() {
+	deprovisionCmd.Flags().StringSlice("regex", []string{},
+		`Select deployments with names matching this
+			regular expression. This flag can be repeated.`)
+	if err := viper.BindPFlag("deprovision-regex", deprovisionCmd.Flags().Lookup("regex")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	deprovisionCmd.Flags().StringSlice("type", []string{},
+		`Only select deployments of this type. This
+			flag can be repeated.`)
+	if err := viper.BindPFlag("deprovision-type", deprovisionCmd.Flags().Lookup("type")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	deprovisionCmd.Flags().String("notes", "",
+		`Only select deployments with notes matching
+			this regular expression`)
+	if err := viper.BindPFlag("deprovision-notes", deprovisionCmd.Flags().Lookup("notes")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	deprovisionCmd.Flags().Bool("all", false,
+		`Let '--type' and '--notes' select from every
+			deployment in the account when no names,
+			patterns or configuration are provided`)
+	if err := viper.BindPFlag("deprovision-all", deprovisionCmd.Flags().Lookup("all")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	deprovisionCmd.Flags().StringSlice("cluster", []string{},
+		`Only select deployments declared in this
+			cluster by the configuration provided. This
+			flag can be repeated.`)
+	if err := viper.BindPFlag("deprovision-cluster", deprovisionCmd.Flags().Lookup("cluster")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 }
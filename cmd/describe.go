@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/benjdewan/pachelbel/config"
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/errorqueue"
+	"github.com/benjdewan/pachelbel/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <name|id>...",
+	Short: "Show everything about compose deployments",
+	Long: `pachelbel describe reads a list of deployment names and/or IDs as
+arguments, and prints everything pachelbel can find out about each of them:
+connection endpoints, the CA certificate fingerprint, scaling, available
+version transitions, team roles, whitelist and recent recipes.
+
+Endpoints are shown the way 'pachelbel provision' writes them, without
+passwords. Use '--config' to apply the endpoint_map objects in the given
+configuration to them.`,
+	Run: runDescribe,
+}
+
+type describedTransition struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Method string `json:"method"`
+}
+
+type describedWhitelistEntry struct {
+	CIDR        string `json:"cidr"`
+	Description string `json:"description,omitempty"`
+}
+
+type describedRecipe struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	Duration  float64   `json:"duration_seconds"`
+}
+
+// codebeat:disable[TOO_MANY_IVARS]
+
+// deploymentDescription is the output of describe for a single deployment
+type deploymentDescription struct {
+	ID            string                    `json:"id"`
+	Name          string                    `json:"name"`
+	Type          string                    `json:"type"`
+	Version       string                    `json:"version"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	Notes         string                    `json:"notes,omitempty"`
+	WebUI         string                    `json:"web_ui,omitempty"`
+	Endpoints     []string                  `json:"endpoints"`
+	CAFingerprint string                    `json:"ca_certificate_sha256,omitempty"`
+	Units         statusUnits               `json:"units"`
+	Transitions   []describedTransition     `json:"version_transitions"`
+	TeamRoles     map[string][]string       `json:"team_roles"`
+	Whitelist     []describedWhitelistEntry `json:"whitelist"`
+	Recipes       []describedRecipe         `json:"recipes"`
+}
+
+// codebeat:enable[TOO_MANY_IVARS]
+
+func runDescribe(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		log.Fatal("The 'describe' command requires at least one deployment name or ID as input")
+	}
+	format := viper.GetString("describe-output")
+	if format != "text" && format != "json" {
+		log.Fatalf("'%s' is not a valid output format. Expected 'text' or 'json'", format)
+	}
+	endpointMap, err := config.DeclaredEndpointMap(viper.GetStringSlice("describe-config"))
+	if err != nil {
+		fatal(err)
+	}
+
+	cxn, err := openConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		if closeErr := cxn.Close(); closeErr != nil {
+			panic(closeErr)
+		}
+	}()
+
+	q := errorqueue.New()
+	builder := output.New(endpointMap)
+	descriptions := []deploymentDescription{}
+	for _, arg := range args {
+		description, err := cxn.Describe(arg, viper.GetInt("describe-recipes"))
+		if err != nil {
+			q.Enqueue(err)
+			continue
+		}
+		described, err := describe(builder, description, time.Now())
+		if err != nil {
+			q.Enqueue(err)
+			continue
+		}
+		descriptions = append(descriptions, described)
+	}
+
+	if format == "json" {
+		err = printDescriptionsJSON(descriptions)
+	} else {
+		err = printDescriptions(os.Stdout, descriptions)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if err = q.Flush(); err != nil {
+		fatal(err)
+	}
+}
+
+func describe(builder *output.Builder, d connection.Description, now time.Time) (deploymentDescription, error) {
+	described := deploymentDescription{
+		ID:          d.ID,
+		Name:        d.Name,
+		Type:        d.Type,
+		Version:     d.Version,
+		CreatedAt:   d.Deployment.CreatedAt,
+		Notes:       d.Notes,
+		WebUI:       d.Deployment.Links.ComposeWebUILink.HREF,
+		Units:       statusUnits{Allocated: d.Scaling, Used: d.UtilizedScaling},
+		Transitions: []describedTransition{},
+		TeamRoles:   d.TeamRoles,
+		Whitelist:   []describedWhitelistEntry{},
+		Recipes:     []describedRecipe{},
+	}
+
+	var err error
+	if described.Endpoints, err = builder.Endpoints(d.Deployment); err != nil {
+		return described, err
+	}
+	if described.CAFingerprint, err = output.CAFingerprint(d.Deployment.CACertificateBase64); err != nil {
+		return described, err
+	}
+	for _, transition := range d.Transitions {
+		described.Transitions = append(described.Transitions, describedTransition{
+			From:   transition.FromVersion,
+			To:     transition.ToVersion,
+			Method: transition.Method,
+		})
+	}
+	for _, entry := range d.Whitelist {
+		described.Whitelist = append(described.Whitelist, describedWhitelistEntry{
+			CIDR:        entry.CIDR,
+			Description: entry.Description,
+		})
+	}
+	for _, recipe := range d.Recipes {
+		described.Recipes = append(described.Recipes, describedRecipe{
+			ID:        recipe.ID,
+			Name:      recipe.Name,
+			Status:    recipe.Status,
+			CreatedAt: recipe.CreatedAt,
+			Duration:  connection.RecipeDuration(recipe, now).Seconds(),
+		})
+	}
+	return described, nil
+}
+
+func printDescriptionsJSON(descriptions []deploymentDescription) error {
+	data, err := json.MarshalIndent(descriptions, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Println(string(data))
+	return err
+}
+
+func printDescriptions(w io.Writer, descriptions []deploymentDescription) error {
+	for i, d := range descriptions {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if err := printDescription(w, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printDescription(w io.Writer, d deploymentDescription) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	lines := []string{
+		fmt.Sprintf("Name:\t%s", d.Name),
+		fmt.Sprintf("ID:\t%s", d.ID),
+		fmt.Sprintf("Type:\t%s", d.Type),
+		fmt.Sprintf("Version:\t%s", d.Version),
+		fmt.Sprintf("Created:\t%s", d.CreatedAt.Format(time.RFC3339)),
+		fmt.Sprintf("Web UI:\t%s", orDash(d.WebUI)),
+		fmt.Sprintf("Notes:\t%s", orDash(d.Notes)),
+		fmt.Sprintf("Units:\t%d used of %d allocated", d.Units.Used, d.Units.Allocated),
+		fmt.Sprintf("CA SHA-256:\t%s", orDash(d.CAFingerprint)),
+		fmt.Sprintf("Team roles:\t%s", orDash(formatTeamRoles(d.TeamRoles))),
+		"Endpoints:",
+	}
+	for _, endpoint := range d.Endpoints {
+		lines = append(lines, "  "+endpoint)
+	}
+	lines = append(lines, "Version transitions:")
+	for _, transition := range d.Transitions {
+		lines = append(lines, fmt.Sprintf("  %s -> %s\t%s", transition.From, transition.To, transition.Method))
+	}
+	lines = append(lines, "Whitelist:")
+	for _, entry := range d.Whitelist {
+		lines = append(lines, fmt.Sprintf("  %s\t%s", entry.CIDR, entry.Description))
+	}
+	lines = append(lines, "Recent recipes:")
+	for _, recipe := range d.Recipes {
+		lines = append(lines, fmt.Sprintf("  %s\t%s\t%s\t%s\t%v", recipe.ID, recipe.Name, recipe.Status,
+			recipe.CreatedAt.Format(time.RFC3339), time.Duration(recipe.Duration)*time.Second))
+	}
+
+	if _, err := fmt.Fprintln(tw, strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+func init() {
+	describeCmd.Flags().StringP("output", "o", "text",
+		`The output format: 'text' or 'json'`)
+	if err := viper.BindPFlag("describe-output", describeCmd.Flags().Lookup("output")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	describeCmd.Flags().StringSlice("config", []string{},
+		`Apply the endpoint_map objects in this
+			configuration file or directory to the
+			endpoints shown. This flag can be repeated.`)
+	if err := viper.BindPFlag("describe-config", describeCmd.Flags().Lookup("config")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	describeCmd.Flags().Int("recipes", 10,
+		`The number of recent recipes to show`)
+	if err := viper.BindPFlag("describe-recipes", describeCmd.Flags().Lookup("recipes")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	RootCmd.AddCommand(describeCmd)
+}
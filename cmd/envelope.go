@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/benjdewan/pachelbel/envelope"
+)
+
+// buildEnvelope returns an Envelope for encrypting and/or decrypting output
+// or nil if no passphrase, private key, or recipients were provided.
+func buildEnvelope(passphraseEnv, passphraseFile, identityFile string, recipients []string) (*envelope.Envelope, error) {
+	if len(passphraseEnv) == 0 && len(passphraseFile) == 0 && len(identityFile) == 0 && len(recipients) == 0 {
+		return nil, nil
+	}
+	e := envelope.New()
+
+	passphrase, err := readPassphrase(passphraseEnv, passphraseFile)
+	if err != nil {
+		return nil, err
+	}
+	e.SetPassphrase(passphrase)
+
+	if len(identityFile) > 0 {
+		identity, err := ioutil.ReadFile(identityFile) // #nosec
+		if err != nil {
+			return nil, err
+		}
+		if err = e.SetIdentity(string(bytes.TrimSpace(identity))); err != nil {
+			return nil, fmt.Errorf("Unable to read '%s': %v", identityFile, err)
+		}
+	}
+
+	for _, recipient := range recipients {
+		if err := e.AddRecipient(recipient); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func readPassphrase(passphraseEnv, passphraseFile string) ([]byte, error) {
+	if len(passphraseEnv) > 0 && len(passphraseFile) > 0 {
+		return nil, errors.New("A passphrase can be read from an environment variable or a file, but not both")
+	} else if len(passphraseEnv) > 0 {
+		passphrase, ok := os.LookupEnv(passphraseEnv)
+		if !ok || len(passphrase) == 0 {
+			return nil, fmt.Errorf("The environment variable '%s' is not set", passphraseEnv)
+		}
+		return []byte(passphrase), nil
+	} else if len(passphraseFile) > 0 {
+		passphrase, err := ioutil.ReadFile(passphraseFile) // #nosec
+		if err != nil {
+			return nil, err
+		}
+		passphrase = bytes.TrimRight(passphrase, "\r\n")
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("The passphrase file '%s' is empty", passphraseFile)
+		}
+		return passphrase, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/benjdewan/pachelbel/config"
+	"github.com/benjdewan/pachelbel/connection"
+)
+
+// Exit codes returned by pachelbel. When several errors occur the most
+// severe class present determines the exit code; higher codes are more
+// severe.
+const (
+	exitGeneric       = 1
+	exitValidation    = 2
+	exitAPI           = 3
+	exitRecipeTimeout = 4
+	exitRecipeFailed  = 5
+)
+
+// fatal logs err and exits with the code matching its class. It replaces
+// log.Fatal() anywhere an error may have come from the Compose API or the
+// configuration.
+func fatal(err error) {
+	log.Print(err)
+	os.Exit(exitCode(err))
+}
+
+func exitCode(err error) int {
+	var (
+		failed     *connection.RecipeFailedError
+		timeout    *connection.RecipeTimeoutError
+		api        *connection.APIError
+		validation *config.ValidationError
+	)
+	switch {
+	case errors.As(err, &failed):
+		return exitRecipeFailed
+	case errors.As(err, &timeout):
+		return exitRecipeTimeout
+	case errors.As(err, &api):
+		return exitAPI
+	case errors.As(err, &validation):
+		return exitValidation
+	default:
+		return exitGeneric
+	}
+}
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/benjdewan/pachelbel/config"
 	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/journal"
+	"github.com/benjdewan/pachelbel/output"
+	"github.com/benjdewan/pachelbel/progress"
+	"github.com/benjdewan/pachelbel/report"
 	"github.com/benjdewan/pachelbel/runner"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -28,10 +34,9 @@ configuration no actions are taken.`,
 func runProvision(cmd *cobra.Command, args []string) {
 	assertCanStart(args)
 
-	cxn, err := connection.New(viper.GetString("api-key"),
-		viper.GetString("log-file"))
+	cxn, err := openConnection()
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
 	defer func() {
 		if closeErr := cxn.Close(); closeErr != nil {
@@ -39,28 +44,125 @@ func runProvision(cmd *cobra.Command, args []string) {
 		}
 	}()
 
+	if !viper.GetBool("dry-run") {
+		// Deployments with recipes left running by an earlier run must be
+		// read after those recipes finish, or they will be changed again.
+		if err = resumePending(cxn); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
 	cfg, err := readConfigs(cxn, args)
 	if err != nil {
-		log.Fatal(err)
-	} else if len(cfg.Runners) == 0 {
-		fmt.Println("Nothing to do")
+		fatal(err)
+	}
+	printWarnings(cfg.Warnings)
+	for _, decision := range cfg.Decisions {
+		fmt.Fprintln(os.Stderr, decision)
+	}
+	if len(cfg.Runners) == 0 {
+		fmt.Fprintln(progressOutput(), "Nothing to do")
 		return
 	}
 
-	if err := runner.
-		NewController(cxn, viper.GetBool("dry-run")).
-		Run(cfg.Runners); err != nil {
-		log.Fatal(err)
+	display, err := progress.NewDisplay(viper.GetString("progress"), progressOutput())
+	if err != nil {
+		fatal(err)
+	}
+	ctl := runner.NewController(cxn, display, viper.GetBool("dry-run"))
+	for _, pending := range cfg.Pending {
+		ctl.Report().AddPending(report.Pending{Deployment: pending.Deployment, Description: pending.Description})
+	}
+	runErr := ctl.Run(cfg.Runners)
+	printWarnings(cxn.TakeWarnings())
+	writeReports(ctl.Report())
+	if runErr != nil {
+		fatal(runErr)
 	}
 
 	writeOutput(cxn, cfg.EndpointMap)
 }
 
+// openConnection connects to the Compose API and opens the state journal
+func openConnection() (*connection.Connection, error) {
+	cxn, err := connection.New(viper.GetString("api-key"), connection.Logging{
+		File:       viper.GetString("log-file"),
+		Unredacted: viper.GetBool("log-unredacted"),
+		Format:     viper.GetString("log-format"),
+	})
+	if err != nil {
+		return cxn, err
+	}
+	j, err := journal.Open(viper.GetString("state-file"))
+	if err != nil {
+		return cxn, err
+	}
+	return cxn.WithJournal(j).WithActiveRecipes(viper.GetString("active-recipes"))
+}
+
 func writeOutput(cxn *connection.Connection, endpointMap map[string]string) {
-	dst := viper.GetString("output")
+	dst, err := outputDestination()
+	if err != nil {
+		fatal(err)
+	}
 	if err := cxn.ConnectionYAML(endpointMap, dst); err != nil {
-		log.Fatal(err)
+		fatal(err)
+	}
+}
+
+// progressOutput is where progress and the run summary are written. It is
+// stderr when the connection YAML is written to stdout, so the two are not
+// mixed.
+func progressOutput() *os.File {
+	if viper.GetString("output") == "-" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func writeReports(r *report.Report) {
+	if err := r.WriteSummary(progressOutput()); err != nil {
+		fatal(err)
+	}
+	for _, path := range viper.GetStringSlice("report") {
+		if err := r.WriteFile(path); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+func outputDestination() (output.Destination, error) {
+	dst := output.Destination{
+		File:  viper.GetString("output"),
+		Merge: viper.GetBool("output-merge"),
+	}
+	if dst.Merge && dst.File == "-" {
+		return dst, errors.New("--output-merge cannot be used when writing output to stdout")
+	}
+
+	var err error
+	if dst.Mode, err = outputMode(viper.GetString("output-mode")); err != nil {
+		return dst, err
+	}
+
+	dst.Envelope, err = buildEnvelope(viper.GetString("output-passphrase-env"),
+		viper.GetString("output-passphrase-file"),
+		viper.GetString("output-identity-file"),
+		viper.GetStringSlice("output-recipient"))
+	if err != nil {
+		return dst, err
+	} else if dst.Envelope != nil && !dst.Envelope.CanSeal() {
+		return dst, errors.New("--output-identity-file can only be used to merge into output that is also encrypted with a passphrase or for recipients")
+	}
+	return dst, nil
+}
+
+func outputMode(rawMode string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(rawMode, 8, 32)
+	if err != nil || os.FileMode(mode)&^os.ModePerm != 0 {
+		return 0, fmt.Errorf("'%s' is not a valid octal file mode for --output-mode", rawMode)
 	}
+	return os.FileMode(mode), nil
 }
 
 func readConfigs(cxn *connection.Connection, paths []string) (*config.Config, error) {
@@ -82,6 +184,11 @@ func readConfigs(cxn *connection.Connection, paths []string) (*config.Config, er
 
 	config.CXN = cxn
 
+	config.ScaleDownHeadroom = viper.GetInt("scale-down-headroom")
+	if config.ScaleDownHeadroom < 0 {
+		return nil, &config.ValidationError{Problems: []string{"--scale-down-headroom cannot be negative"}}
+	}
+
 	config.BuildClusterFilter(viper.GetStringSlice("cluster"))
 	config.BuildDatacenterFilter(viper.GetStringSlice("datacenter"))
 
@@ -99,6 +206,21 @@ func init() {
 	addClusterFlag()
 	addDatacenterFlag()
 	addOutputFlag()
+	addOutputEncryptionFlags()
+	addReportFlag()
+	addScaleDownHeadroomFlag()
+}
+
+func addScaleDownHeadroomFlag() {
+	provisionCmd.Flags().Int("scale-down-headroom", 20,
+		`The percentage of the units a deployment
+				 currently utilizes that must remain free
+				 after scaling it down. Only deployments with
+				 'allow_scale_down: true' are scaled down.`)
+	if err := viper.BindPFlag("scale-down-headroom", provisionCmd.Flags().Lookup("scale-down-headroom")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 }
 
 func addClusterFlag() {
@@ -134,9 +256,81 @@ func addDatacenterFlag() {
 func addOutputFlag() {
 	provisionCmd.Flags().StringP("output", "o", "./connection-info.yml",
 		`The file to write connection string
-				 information to.`)
+				 information to. Use '-' to write to stdout.`)
 	if err := viper.BindPFlag("output", provisionCmd.Flags().Lookup("output")); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	provisionCmd.Flags().String("output-mode", fmt.Sprintf("%04o", output.DefaultMode),
+		`The file permissions, in octal, to give the
+				 output file. The output contains credentials,
+				 so by default only the owner can read it.`)
+	if err := viper.BindPFlag("output-mode", provisionCmd.Flags().Lookup("output-mode")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	provisionCmd.Flags().Bool("output-merge", false,
+		`Merge connection information into the existing
+				 output file instead of overwriting it. Entries
+				 for deployments provisioned in this run are
+				 replaced, entries for deployments deprovisioned
+				 in this run are removed, and every other entry
+				 is kept.`)
+	if err := viper.BindPFlag("output-merge", provisionCmd.Flags().Lookup("output-merge")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func addOutputEncryptionFlags() {
+	provisionCmd.Flags().String("output-passphrase-env", "",
+		`Encrypt the output file using the passphrase
+				 stored in the named environment variable.`)
+	if err := viper.BindPFlag("output-passphrase-env", provisionCmd.Flags().Lookup("output-passphrase-env")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	provisionCmd.Flags().String("output-passphrase-file", "",
+		`Encrypt the output file using the passphrase
+				 stored in the specified file.`)
+	if err := viper.BindPFlag("output-passphrase-file", provisionCmd.Flags().Lookup("output-passphrase-file")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	provisionCmd.Flags().StringSlice("output-recipient", []string{},
+		`Encrypt the output file for the specified
+				 base64 encoded x25519 public key. Any recipient
+				 can decrypt the output using their private key.
+
+				 This flag can be repeated to specify multiple
+				 recipients, and can be combined with a
+				 passphrase.`)
+	if err := viper.BindPFlag("output-recipient", provisionCmd.Flags().Lookup("output-recipient")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	provisionCmd.Flags().String("output-identity-file", "",
+		`A file containing a base64 encoded x25519
+				 private key used to decrypt the existing
+				 output file when --output-merge is set.`)
+	if err := viper.BindPFlag("output-identity-file", provisionCmd.Flags().Lookup("output-identity-file")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func addReportFlag() {
+	provisionCmd.Flags().StringSlice("report", []string{},
+		`Write a report of every deployment processed,
+				 with its action, result, duration and recipe
+				 IDs, to the specified file. Files ending in
+				 '.xml' are written as JUnit XML and files
+				 ending in '.json' as JSON.
+
+				 This flag can be repeated to write multiple
+				 reports.`)
+	if err := viper.BindPFlag("report", provisionCmd.Flags().Lookup("report")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/progress"
+	"github.com/benjdewan/pachelbel/runner"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var reapCmd = &cobra.Command{
+	Use:   "reap",
+	Short: "Deprovision deployments whose deletion grace period has expired",
+	Long: `pachelbel reap finds every deployment marked as pending deletion by
+'pachelbel deprovision --grace' whose grace period has expired, and
+deprovisions it. Deployments still within their grace period are listed but
+left alone.
+
+The deployments to deprovision are listed and pachelbel asks for
+confirmation first. Use '--yes' to skip the confirmation when running
+non-interactively, e.g. on a schedule.
+
+Each deployment's notes are read again just before it is deprovisioned, so
+deployments undeleted in the meantime are not deprovisioned.`,
+	Run: runReap,
+}
+
+func runReap(cmd *cobra.Command, args []string) {
+	cxn, err := openConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		if closeErr := cxn.Close(); closeErr != nil {
+			panic(closeErr)
+		}
+	}()
+
+	deployments, err := cxn.Deployments()
+	if err != nil {
+		fatal(err)
+	}
+	expired, pending := connection.PendingDeletion(deployments, time.Now())
+	for _, deployment := range pending {
+		expiry, _ := connection.DeletionExpiry(deployment.Notes)
+		fmt.Fprintf(os.Stderr, "'%s' is pending deletion until %s\n", deployment.Name, expiry.Format(time.RFC3339))
+	}
+	if len(expired) == 0 {
+		fmt.Println("Nothing to do")
+		return
+	}
+	if err = printSelected(os.Stdout, expired); err != nil {
+		fatal(err)
+	}
+	if !viper.GetBool("dry-run") && !viper.GetBool("reap-yes") {
+		if err = confirmDeprovision(len(expired), 0); err != nil {
+			fatal(err)
+		}
+	}
+
+	timeout := float64(viper.GetInt("reap-timeout"))
+	if !viper.GetBool("reap-wait") {
+		timeout = 0
+	}
+	display, err := progress.NewDisplay(viper.GetString("progress"), os.Stdout)
+	if err != nil {
+		fatal(err)
+	}
+	ctl := runner.NewController(cxn, display, viper.GetBool("dry-run"))
+	runErr := ctl.Run(targetRunners(expired, timeout, 0, runner.ActionReap, runner.Reap))
+	printWarnings(cxn.TakeWarnings())
+	if err := ctl.Report().WriteSummary(os.Stdout); err != nil {
+		fatal(err)
+	}
+	if runErr != nil {
+		fatal(runErr)
+	}
+}
+
+func init() {
+	reapCmd.Flags().BoolP("wait", "w", false,
+		`Wait for deprovisioning recipes to complete before
+			returning`)
+	if err := viper.BindPFlag("reap-wait", reapCmd.Flags().Lookup("wait")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	reapCmd.Flags().IntP("timeout", "t", 300,
+		`The amount of time to wait, in seconds, for
+			deprovisioning recipes to complete.
+
+			Ignored if '--wait' is not set`)
+	if err := viper.BindPFlag("reap-timeout", reapCmd.Flags().Lookup("timeout")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	reapCmd.Flags().BoolP("yes", "y", false,
+		`Deprovision the expired deployments without
+			asking for confirmation`)
+	if err := viper.BindPFlag("reap-yes", reapCmd.Flags().Lookup("yes")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	RootCmd.AddCommand(reapCmd)
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	compose "github.com/benjdewan/gocomposeapi"
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/errorqueue"
+	"github.com/benjdewan/pachelbel/progress"
+	"github.com/benjdewan/pachelbel/runner"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var recipesCmd = &cobra.Command{
+	Use:   "recipes",
+	Short: "Inspect and wait on compose recipes",
+}
+
+var recipesListCmd = &cobra.Command{
+	Use:   "list <name|id>...",
+	Short: "List the recipes run on compose deployments",
+	Long: `pachelbel recipes list reads a list of deployment names and/or IDs
+as arguments, and prints the ID, name, status, creation time and duration of
+every recipe run on each of them, newest first.`,
+	Run: runRecipesList,
+}
+
+var recipesWaitCmd = &cobra.Command{
+	Use:   "wait <recipe id>...",
+	Short: "Wait on compose recipes",
+	Long: `pachelbel recipes wait reads a list of recipe IDs as arguments, and
+waits on each of them until it finishes or '--timeout' elapses, showing its
+status as it runs. The recipes do not need to have been started by pachelbel.
+
+It exits with status 0 only if every recipe finished successfully.`,
+	Run: runRecipesWait,
+}
+
+// recipeTarget is a recipe to wait on
+type recipeTarget struct {
+	id      string
+	timeout float64
+}
+
+func (r recipeTarget) GetName() string {
+	return r.id
+}
+
+func (r recipeTarget) GetType() string {
+	return "recipe"
+}
+
+func (r recipeTarget) GetRecipeID() string {
+	return r.id
+}
+
+func (r recipeTarget) GetTimeout() float64 {
+	return r.timeout
+}
+
+func runRecipesList(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		log.Fatal("At least one deployment name or ID is required as input")
+	}
+
+	cxn, err := openConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		if closeErr := cxn.Close(); closeErr != nil {
+			panic(closeErr)
+		}
+	}()
+
+	q := errorqueue.New()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if _, err = fmt.Fprintln(tw, "DEPLOYMENT\tRECIPE\tNAME\tSTATUS\tCREATED\tDURATION"); err != nil {
+		fatal(err)
+	}
+	for _, arg := range args {
+		existing, err := cxn.ExistingDeployment(arg)
+		if err != nil {
+			q.Enqueue(err)
+			continue
+		}
+		recipes, err := cxn.Recipes(existing.ID, existing.Name)
+		if err != nil {
+			q.Enqueue(err)
+			continue
+		}
+		if err = printRecipes(tw, existing.Name, recipes); err != nil {
+			fatal(err)
+		}
+	}
+	if err = tw.Flush(); err != nil {
+		fatal(err)
+	}
+	if err = q.Flush(); err != nil {
+		fatal(err)
+	}
+}
+
+func printRecipes(tw *tabwriter.Writer, name string, recipes []compose.Recipe) error {
+	now := time.Now()
+	for _, recipe := range recipes {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%v\n", name, recipe.ID, recipe.Name, recipe.Status,
+			recipe.CreatedAt.UTC().Format(time.RFC3339), connection.RecipeDuration(recipe, now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runRecipesWait(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		log.Fatal("At least one recipe ID is required as input")
+	}
+	timeout := float64(viper.GetInt("recipes-timeout"))
+	if timeout <= 0 {
+		log.Fatal("--timeout must be greater than 0")
+	}
+
+	cxn, err := openConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		if closeErr := cxn.Close(); closeErr != nil {
+			panic(closeErr)
+		}
+	}()
+
+	runners := []runner.Runner{}
+	for _, arg := range args {
+		runners = append(runners, runner.Runner{
+			Target: recipeTarget{id: arg, timeout: timeout},
+			Action: runner.ActionWait,
+			Run:    runner.WaitRecipe,
+		})
+	}
+
+	display, err := progress.NewDisplay(viper.GetString("progress"), os.Stdout)
+	if err != nil {
+		fatal(err)
+	}
+	ctl := runner.NewController(cxn, display, viper.GetBool("dry-run"))
+	runErr := ctl.Run(runners)
+	printWarnings(cxn.TakeWarnings())
+	if err := ctl.Report().WriteSummary(os.Stdout); err != nil {
+		fatal(err)
+	}
+	if runErr != nil {
+		fatal(runErr)
+	}
+}
+
+func init() {
+	recipesWaitCmd.Flags().IntP("timeout", "t", 900,
+		`The amount of time to wait, in seconds, for
+			each recipe to finish`)
+	if err := viper.BindPFlag("recipes-timeout", recipesWaitCmd.Flags().Lookup("timeout")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	recipesCmd.AddCommand(recipesListCmd)
+	recipesCmd.AddCommand(recipesWaitCmd)
+	RootCmd.AddCommand(recipesCmd)
+}
@@ -58,7 +58,47 @@ func init() {
 	RootCmd.PersistentFlags().StringP("log-file", "l", "",
 		`If specified pachelbel will enable logging for
 				all Compose API requests and write them, as well
-				as the reponses, to the specified log file`)
+				as the reponses, to the specified log file.
+				Credentials are masked unless --log-unredacted
+				is set.`)
+	RootCmd.PersistentFlags().Bool("log-unredacted", false,
+		`By default API tokens, passwords and certificates
+				are masked in the log file. Set this flag to log
+				them as-is. Only use this for local debugging.`)
+	RootCmd.PersistentFlags().String("log-format", "text",
+		`The format of the log file. 'text' logs the raw
+				requests and responses. 'json' logs one JSON
+				object per API call with its method, path,
+				status, HTTP status code, duration, attempt
+				number and the deployment it was made for.
+
+				Only recipe status polls and deployment lookups
+				are repeated, so 'attempt' is 1 for every other
+				call.`)
+	RootCmd.PersistentFlags().String("progress", "auto",
+		`How to display progress. 'tty' redraws one row
+				per deployment with its recipe status in place.
+				'bars' prints a row of progress bars to stdout
+				every few seconds. 'plain' prints one line per
+				state change and 'json' prints one JSON object
+				per state change, both to stderr. 'auto' uses
+				'tty' when stdout is a terminal and 'bars'
+				otherwise. When 'provision' writes its output
+				to stdout ('--output -') progress is written to
+				stderr instead.`)
+	RootCmd.PersistentFlags().String("state-file", ".pachelbel-state.json",
+		`The file pachelbel records the recipes it starts
+				in until they finish. If pachelbel exits early
+				the next run waits on any recipes recorded
+				here before making changes, and refuses to
+				change deployments whose recipes are still
+				running. Set this to '' to disable it.`)
+	RootCmd.PersistentFlags().String("active-recipes", "wait",
+		`What to do when a deployment pachelbel needs to
+				resize, upgrade or deprovision already has
+				recipes running on it. 'wait' waits for them to
+				finish first. 'fail' fails without changing the
+				deployment.`)
 	RootCmd.PersistentFlags().BoolP("dry-run", "n", false,
 		`Simulate a pachelbel command run without making any
 				 real changes.`)
@@ -71,6 +111,26 @@ func init() {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	if err := viper.BindPFlag("log-unredacted", RootCmd.PersistentFlags().Lookup("log-unredacted")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := viper.BindPFlag("log-format", RootCmd.PersistentFlags().Lookup("log-format")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := viper.BindPFlag("state-file", RootCmd.PersistentFlags().Lookup("state-file")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := viper.BindPFlag("active-recipes", RootCmd.PersistentFlags().Lookup("active-recipes")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := viper.BindPFlag("progress", RootCmd.PersistentFlags().Lookup("progress")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	if err := viper.BindPFlag("api-key", RootCmd.PersistentFlags().Lookup("api-key")); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
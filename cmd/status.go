@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/benjdewan/pachelbel/config"
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/errorqueue"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [name|id|config path]...",
+	Short: "Summarize the current state of compose deployments",
+	Long: `pachelbel status reads a mixed list of deployment names, deployment
+IDs and YAML configuration files or directories containing them as arguments,
+and prints the current state of each deployment without changing anything.
+
+Configuration files contribute every deployment they declare. Other arguments,
+including files without a '.yml' or '.yaml' extension, are read as names or
+IDs. Use '--config' to only show the deployments declared in the given
+configuration.
+
+Compose does not report where a deployment is provisioned, so the location
+shown is the cluster, datacenter or tags declared in configuration, if any.`,
+	Run: runStatus,
+}
+
+type statusUnits struct {
+	Allocated int `json:"allocated"`
+	Used      int `json:"used"`
+}
+
+// deploymentStatus is a row of status output
+type deploymentStatus struct {
+	Name      string              `json:"name"`
+	Found     bool                `json:"found"`
+	ID        string              `json:"id,omitempty"`
+	Type      string              `json:"type,omitempty"`
+	Version   string              `json:"version,omitempty"`
+	Upgrades  []string            `json:"upgrades"`
+	Units     *statusUnits        `json:"units,omitempty"`
+	Location  string              `json:"location,omitempty"`
+	TeamRoles map[string][]string `json:"team_roles,omitempty"`
+}
+
+func runStatus(cmd *cobra.Command, args []string) {
+	format := viper.GetString("status-output")
+	if format != "table" && format != "json" {
+		log.Fatalf("'%s' is not a valid output format. Expected 'table' or 'json'", format)
+	}
+
+	targets, err := statusTargets(args, viper.GetStringSlice("status-config"))
+	if err != nil {
+		fatal(err)
+	} else if len(targets) == 0 {
+		log.Fatal("The 'status' command requires at least one deployment name, ID or configuration path as input")
+	}
+
+	cxn, err := openConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		if closeErr := cxn.Close(); closeErr != nil {
+			panic(closeErr)
+		}
+	}()
+
+	q := errorqueue.New()
+	statuses := []deploymentStatus{}
+	for _, target := range targets {
+		status, err := lookupStatus(cxn, target)
+		if err != nil {
+			q.Enqueue(err)
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+
+	if format == "json" {
+		err = printStatusJSON(statuses)
+	} else {
+		err = printStatusTable(statuses)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if err = q.Flush(); err != nil {
+		fatal(err)
+	}
+}
+
+// statusTargets splits arguments into deployment names or IDs and the
+// deployments declared in configuration paths. If '--config' is used only
+// deployments declared there are returned.
+func statusTargets(args, configPaths []string) ([]config.DeclaredDeployment, error) {
+	names := []string{}
+	for _, arg := range args {
+		if config.IsConfigPath(arg) {
+			configPaths = append(configPaths, arg)
+		} else {
+			names = append(names, arg)
+		}
+	}
+
+	declared, err := config.Declared(configPaths)
+	if err != nil {
+		return nil, err
+	}
+	if len(viper.GetStringSlice("status-config")) == 0 {
+		for _, name := range names {
+			declared = append(declared, config.DeclaredDeployment{Name: name})
+		}
+		return declared, nil
+	} else if len(names) == 0 {
+		return declared, nil
+	}
+
+	wanted := make(map[string]struct{})
+	for _, name := range names {
+		wanted[name] = struct{}{}
+	}
+	filtered := []config.DeclaredDeployment{}
+	for _, d := range declared {
+		if _, ok := wanted[d.Name]; ok {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered, nil
+}
+
+// statusSource is the part of a *connection.Connection status needs
+type statusSource interface {
+	ExistingDeployment(idOrName string) (connection.ExistingDeployment, error)
+	TeamRoles(id, name string) (map[string][]string, error)
+}
+
+func lookupStatus(cxn statusSource, target config.DeclaredDeployment) (deploymentStatus, error) {
+	status := deploymentStatus{
+		Name:     target.Name,
+		Type:     target.Type,
+		Upgrades: []string{},
+		Location: target.Location(),
+	}
+
+	existing, err := cxn.ExistingDeployment(target.Name)
+	if _, ok := err.(*connection.NotFoundError); ok {
+		return status, nil
+	} else if err != nil {
+		return status, err
+	}
+
+	status.Found = true
+	status.Name = existing.Name
+	status.ID = existing.ID
+	status.Type = existing.Type
+	status.Version = existing.Version
+	status.Units = &statusUnits{Allocated: existing.Scaling, Used: existing.UtilizedScaling}
+	for _, upgrade := range existing.Upgrades {
+		status.Upgrades = append(status.Upgrades, upgrade.String())
+	}
+	status.TeamRoles, err = cxn.TeamRoles(existing.ID, existing.Name)
+	return status, err
+}
+
+func printStatusJSON(statuses []deploymentStatus) error {
+	data, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Println(string(data))
+	return err
+}
+
+func printStatusTable(statuses []deploymentStatus) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "NAME\tTYPE\tVERSION\tUPGRADES\tUNITS (USED/ALLOCATED)\tLOCATION\tTEAM ROLES"); err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		row := []string{status.Name, orDash(status.Type), "not found", "-", "-", orDash(status.Location), "-"}
+		if status.Found {
+			row[2] = status.Version
+			row[3] = orDash(strings.Join(status.Upgrades, ","))
+			row[4] = fmt.Sprintf("%d/%d", status.Units.Used, status.Units.Allocated)
+			row[6] = orDash(formatTeamRoles(status.TeamRoles))
+		}
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+func formatTeamRoles(teamRoles map[string][]string) string {
+	roles := []string{}
+	for role, teams := range teamRoles {
+		roles = append(roles, fmt.Sprintf("%s=%s", role, strings.Join(teams, ",")))
+	}
+	sort.Strings(roles)
+	return strings.Join(roles, " ")
+}
+
+func orDash(str string) string {
+	if len(str) == 0 {
+		return "-"
+	}
+	return str
+}
+
+func init() {
+	statusCmd.Flags().StringP("output", "o", "table",
+		`The output format: 'table' or 'json'`)
+	if err := viper.BindPFlag("status-output", statusCmd.Flags().Lookup("output")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	statusCmd.Flags().StringSlice("config", []string{},
+		`Only show deployments declared in this
+			configuration file or directory. This flag can
+			be repeated.`)
+	if err := viper.BindPFlag("status-config", statusCmd.Flags().Lookup("config")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	RootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/benjdewan/pachelbel/config"
+	"github.com/benjdewan/pachelbel/connection"
+)
+
+type fakeStatusSource struct {
+	existing map[string]connection.ExistingDeployment
+	err      error
+}
+
+func (f fakeStatusSource) ExistingDeployment(idOrName string) (connection.ExistingDeployment, error) {
+	if f.err != nil {
+		return connection.ExistingDeployment{}, f.err
+	}
+	existing, ok := f.existing[idOrName]
+	if !ok {
+		return existing, &connection.NotFoundError{Deployment: idOrName}
+	}
+	return existing, nil
+}
+
+func (f fakeStatusSource) TeamRoles(id, name string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func TestLookupStatus(t *testing.T) {
+	src := fakeStatusSource{existing: map[string]connection.ExistingDeployment{
+		"pg-01": {ID: "id-01", Name: "pg-01", Type: "postgresql", Scaling: 4, UtilizedScaling: 2},
+	}}
+
+	status, err := lookupStatus(src, config.DeclaredDeployment{Name: "pg-01"})
+	if err != nil || !status.Found || status.ID != "id-01" || status.Units.Allocated != 4 {
+		t.Errorf("Expected 'pg-01' to be found, but saw %+v, %v", status, err)
+	}
+
+	status, err = lookupStatus(src, config.DeclaredDeployment{Name: "missing", Type: "redis"})
+	if err != nil || status.Found || status.Type != "redis" {
+		t.Errorf("Expected a 'not found' row for 'missing', but saw %+v, %v", status, err)
+	}
+
+	src.err = errors.New("Unable to reach the Compose API")
+	if _, err = lookupStatus(src, config.DeclaredDeployment{Name: "pg-01"}); err == nil {
+		t.Errorf("Expected API errors to be returned")
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/benjdewan/pachelbel/errorqueue"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var undeleteCmd = &cobra.Command{
+	Use:   "undelete <name|id>...",
+	Short: "Cancel the deletion of deployments within their grace period",
+	Long: `pachelbel undelete reads a list of deployment names and/or IDs as
+arguments, and removes the pending deletion mark that
+'pachelbel deprovision --grace' added to the notes of each of them, so
+'pachelbel reap' will not deprovision them.
+
+Deployments that are not pending deletion are left alone.`,
+	Run: runUndelete,
+}
+
+func runUndelete(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		log.Fatal("At least one deployment name or ID is required as input")
+	}
+
+	cxn, err := openConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		if closeErr := cxn.Close(); closeErr != nil {
+			panic(closeErr)
+		}
+	}()
+
+	q := errorqueue.New()
+	for _, arg := range args {
+		if viper.GetBool("dry-run") {
+			fmt.Printf("Dry run: would undelete '%s' if it is pending deletion\n", arg)
+			continue
+		}
+		undeleted, err := cxn.Undelete(arg)
+		switch {
+		case err != nil:
+			q.Enqueue(err)
+		case undeleted:
+			fmt.Printf("'%s' is no longer pending deletion\n", arg)
+		default:
+			fmt.Printf("'%s' is not pending deletion\n", arg)
+		}
+	}
+	if err = q.Flush(); err != nil {
+		fatal(err)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(undeleteCmd)
+}
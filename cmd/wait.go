@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/errorqueue"
+	"github.com/benjdewan/pachelbel/journal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Wait on recipes started by an earlier run of pachelbel",
+	Long: `pachelbel wait reads the recipes recorded in the state file by
+earlier runs of pachelbel that did not see them finish, and waits on each
+of them until it finishes or its timeout elapses again.
+
+Recipes that finish, successfully or not, are removed from the state file.
+Use --forget to remove the recipes of the named deployments from the state
+file without waiting on them.`,
+	Run: runWait,
+}
+
+func runWait(cmd *cobra.Command, args []string) {
+	cxn, err := openConnection()
+	if err != nil {
+		fatal(err)
+	}
+	defer func() {
+		if closeErr := cxn.Close(); closeErr != nil {
+			panic(closeErr)
+		}
+	}()
+
+	q := errorqueue.New()
+	for _, name := range viper.GetStringSlice("wait-forget") {
+		if err = cxn.ForgetPending(name); err != nil {
+			q.Enqueue(err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Removed the unfinished recipe for '%s' from the state file\n", name)
+	}
+	if err = resumePending(cxn); err != nil {
+		q.Enqueue(err)
+	}
+	if err = q.Flush(); err != nil {
+		fatal(err)
+	}
+}
+
+func resumePending(cxn *connection.Connection) error {
+	err := cxn.ResumePending(func(entry journal.Entry) {
+		fmt.Fprintf(os.Stderr, "Waiting on the %s recipe %s for '%s' started at %s\n",
+			entry.Operation, entry.RecipeID, entry.Deployment, entry.StartedAt.Format("2006-01-02 15:04:05 MST"))
+	})
+	printWarnings(cxn.TakeWarnings())
+	return err
+}
+
+// printWarnings prints problems that did not stop pachelbel to stderr
+func printWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", warning)
+	}
+}
+
+func init() {
+	waitCmd.Flags().StringSlice("forget", []string{},
+		`Remove the unfinished recipe recorded for each of
+			these deployments from the state file instead of
+			waiting on it`)
+	if err := viper.BindPFlag("wait-forget", waitCmd.Flags().Lookup("forget")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	RootCmd.AddCommand(waitCmd)
+}
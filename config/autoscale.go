@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/runner"
+)
+
+// AutoscaleV1 is the structure corresponding to version 1 of pachelbel's
+// autoscale configuration YAML. It replaces a fixed 'scaling' value with
+// one computed from the units a deployment currently utilizes.
+type AutoscaleV1 struct {
+	Min               int `json:"min"`
+	Max               int `json:"max"`
+	TargetUtilization int `json:"target_utilization"`
+	Step              int `json:"step,omitempty"`
+}
+
+func validateAutoscaleV1(d *deploymentV1) []string {
+	a := d.Autoscale
+	if a == nil {
+		return []string{}
+	}
+
+	errs := []string{}
+	if d.Scaling != 0 {
+		errs = append(errs, "The 'scaling' and 'autoscale' fields cannot both be set")
+	}
+	if a.Min < 1 {
+		errs = append(errs, "The 'autoscale.min' field must be at least 1")
+	}
+	if a.Max < a.Min {
+		errs = append(errs, "The 'autoscale.max' field cannot be less than 'autoscale.min'")
+	}
+	if a.TargetUtilization < 1 || a.TargetUtilization > 100 {
+		errs = append(errs, "The 'autoscale.target_utilization' field must be a percentage between 1 and 100")
+	}
+	if a.Step < 0 {
+		errs = append(errs, "The 'autoscale.step' field must be a positive integer")
+	}
+	return errs
+}
+
+// autoscaleV1 sets the scaling of an existing deployment with an autoscale
+// block, and records the decision and the reason for it. Scaling down is
+// subject to the same 'allow_scale_down' flag and ScaleDownHeadroom as a
+// fixed 'scaling' value.
+func autoscaleV1(d *deploymentV1, existing connection.ExistingDeployment) ([]string, []string) {
+	if errs := validateAutoscaleV1(d); len(errs) != 0 {
+		return []string{}, errs
+	}
+
+	units, reason := d.Autoscale.units(existing.Scaling, existing.UtilizedScaling)
+	if units < existing.Scaling {
+		if !d.AllowScaleDown {
+			d.warnings = append(d.warnings, fmt.Sprintf(
+				"Autoscaling would scale '%s' down from %d to %d units. Ignoring it. Set 'allow_scale_down: true' to scale it down",
+				d.Name, existing.Scaling, units))
+			d.Scaling = 0
+			return []string{}, []string{}
+		}
+		minimum := minimumUnits(existing.UtilizedScaling)
+		if d.Autoscale.Max < minimum {
+			return []string{}, []string{fmt.Sprintf(
+				"Cannot autoscale '%s' to at most %d units. It currently utilizes %d units, so with %d%% headroom it needs at least %d",
+				d.Name, d.Autoscale.Max, existing.UtilizedScaling, ScaleDownHeadroom, minimum)}
+		} else if units < minimum {
+			// Never scale up a deployment pachelbel meant to scale down
+			units = minInt(minimum, existing.Scaling)
+			reason = fmt.Sprintf("%s, but with %d%% headroom it needs at least %d", reason, ScaleDownHeadroom, minimum)
+		}
+	}
+
+	if units == existing.Scaling {
+		d.Scaling = 0
+		return []string{}, []string{}
+	}
+	d.Scaling = units
+	d.decisions = append(d.decisions, fmt.Sprintf("Autoscaling '%s' from %d to %d units: %s",
+		d.Name, existing.Scaling, units, reason))
+	return []string{runner.ActionResize}, []string{}
+}
+
+// units returns the number of units a deployment with the provided allocated
+// and utilized units should be scaled to, and why. It moves towards the
+// units needed to meet the target utilization in whole steps, rounding up
+// when scaling up and down when scaling down, without leaving [min, max].
+func (a AutoscaleV1) units(allocated, utilized int) (int, string) {
+	step := a.Step
+	if step == 0 {
+		step = 1
+	}
+
+	desired := clamp(ceilDiv(utilized*100, a.TargetUtilization), a.Min, a.Max)
+	units := allocated
+	switch {
+	case desired > allocated:
+		units = allocated + ceilDiv(desired-allocated, step)*step
+	case desired < allocated:
+		units = allocated - ((allocated-desired)/step)*step
+	}
+	units = clamp(units, a.Min, a.Max)
+
+	return units, fmt.Sprintf("%d of %d units are utilized, and %d%% utilization needs %d units (min %d, max %d, step %d)",
+		utilized, allocated, a.TargetUtilization, desired, a.Min, a.Max, step)
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	} else if n > max {
+		return max
+	}
+	return n
+}
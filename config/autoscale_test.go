@@ -0,0 +1,137 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/benjdewan/pachelbel/connection"
+)
+
+func TestAutoscaleUnits(t *testing.T) {
+	for i, test := range autoscaleUnitsTests {
+		if actual, _ := test.autoscale.units(test.allocated, test.utilized); actual != test.expected {
+			t.Errorf("Test #%d: Expected %d units but saw %d", i, test.expected, actual)
+		}
+	}
+}
+
+var autoscaleUnitsTests = []struct {
+	autoscale AutoscaleV1
+	allocated int
+	utilized  int
+	expected  int
+}{
+	// 8 utilized units at 80% utilization needs 10 units
+	{
+		autoscale: AutoscaleV1{Min: 2, Max: 20, TargetUtilization: 80},
+		allocated: 8, utilized: 8, expected: 10,
+	},
+	// Scaling up rounds up to a whole step
+	{
+		autoscale: AutoscaleV1{Min: 2, Max: 20, TargetUtilization: 80, Step: 4},
+		allocated: 8, utilized: 8, expected: 12,
+	},
+	// But never exceeds the maximum
+	{
+		autoscale: AutoscaleV1{Min: 2, Max: 11, TargetUtilization: 80, Step: 4},
+		allocated: 8, utilized: 8, expected: 11,
+	},
+	// Scaling down rounds down to a whole step
+	{
+		autoscale: AutoscaleV1{Min: 2, Max: 20, TargetUtilization: 50, Step: 4},
+		allocated: 16, utilized: 3, expected: 8,
+	},
+	// But never goes below the minimum
+	{
+		autoscale: AutoscaleV1{Min: 4, Max: 20, TargetUtilization: 100},
+		allocated: 6, utilized: 1, expected: 4,
+	},
+	// No change within a step of the target
+	{
+		autoscale: AutoscaleV1{Min: 2, Max: 20, TargetUtilization: 50, Step: 4},
+		allocated: 10, utilized: 4, expected: 10,
+	},
+	// Allocations outside of [min, max] are brought back within it
+	{
+		autoscale: AutoscaleV1{Min: 2, Max: 6, TargetUtilization: 80},
+		allocated: 10, utilized: 8, expected: 6,
+	},
+}
+
+func TestValidateAutoscaleV1(t *testing.T) {
+	for i, test := range validateAutoscaleV1Tests {
+		errs := validateAutoscaleV1(&test.config)
+		if test.valid && len(errs) != 0 {
+			t.Errorf("Test #%d: Expected the autoscale block to be valid, but saw: %v", i, errs)
+		} else if !test.valid && len(errs) == 0 {
+			t.Errorf("Test #%d: Expected the autoscale block to be invalid", i)
+		}
+	}
+}
+
+var validateAutoscaleV1Tests = []struct {
+	config deploymentV1
+	valid  bool
+}{
+	{config: deploymentV1{}, valid: true},
+	{config: deploymentV1{Autoscale: &AutoscaleV1{Min: 1, Max: 4, TargetUtilization: 75}}, valid: true},
+	{config: deploymentV1{Scaling: 2, Autoscale: &AutoscaleV1{Min: 1, Max: 4, TargetUtilization: 75}}, valid: false},
+	{config: deploymentV1{Autoscale: &AutoscaleV1{Min: 0, Max: 4, TargetUtilization: 75}}, valid: false},
+	{config: deploymentV1{Autoscale: &AutoscaleV1{Min: 5, Max: 4, TargetUtilization: 75}}, valid: false},
+	{config: deploymentV1{Autoscale: &AutoscaleV1{Min: 1, Max: 4, TargetUtilization: 0}}, valid: false},
+	{config: deploymentV1{Autoscale: &AutoscaleV1{Min: 1, Max: 4, TargetUtilization: 75, Step: -1}}, valid: false},
+}
+
+func TestAutoscaleV1(t *testing.T) {
+	for i, test := range autoscaleV1Tests {
+		d := deploymentV1{Name: "pg-01", Autoscale: &test.autoscale, AllowScaleDown: test.allowScaleDown}
+		existing := connection.ExistingDeployment{Scaling: test.allocated, UtilizedScaling: test.utilized}
+		actions, errs := autoscaleV1(&d, existing)
+		if len(actions) != test.actions || len(errs) != test.errs || len(d.warnings) != test.warnings {
+			t.Errorf("Test #%d: Expected %d action(s), %d error(s) and %d warning(s) but saw %v, %v and %v",
+				i, test.actions, test.errs, test.warnings, actions, errs, d.warnings)
+		} else if d.Scaling != test.units {
+			t.Errorf("Test #%d: Expected scaling to be set to %d but saw %d", i, test.units, d.Scaling)
+		}
+	}
+}
+
+var autoscaleV1Tests = []struct {
+	autoscale      AutoscaleV1
+	allowScaleDown bool
+	allocated      int
+	utilized       int
+	actions        int
+	errs           int
+	warnings       int
+	units          int
+}{
+	// Scaling up needs no permission
+	{
+		autoscale: AutoscaleV1{Min: 2, Max: 20, TargetUtilization: 80},
+		allocated: 8, utilized: 8, actions: 1, units: 10,
+	},
+	// Scaling down without permission is ignored with a warning
+	{
+		autoscale: AutoscaleV1{Min: 2, Max: 20, TargetUtilization: 50},
+		allocated: 16, utilized: 3, warnings: 1, units: 0,
+	},
+	{
+		autoscale:      AutoscaleV1{Min: 2, Max: 20, TargetUtilization: 50},
+		allowScaleDown: true, allocated: 16, utilized: 3, actions: 1, units: 6,
+	},
+	// A target utilization of 100% still leaves headroom when scaling down
+	{
+		autoscale:      AutoscaleV1{Min: 2, Max: 20, TargetUtilization: 100},
+		allowScaleDown: true, allocated: 16, utilized: 10, actions: 1, units: 12,
+	},
+	// But never scales up a deployment it meant to scale down
+	{
+		autoscale:      AutoscaleV1{Min: 2, Max: 20, TargetUtilization: 100},
+		allowScaleDown: true, allocated: 11, utilized: 10, units: 0,
+	},
+	// A maximum below the utilized units plus headroom is an error
+	{
+		autoscale:      AutoscaleV1{Min: 2, Max: 10, TargetUtilization: 80},
+		allowScaleDown: true, allocated: 20, utilized: 15, errs: 1, units: 0,
+	},
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"bufio"
 	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -447,3 +450,100 @@ name: ignored-when-no-existing-deployment-matches`,
 		valid: true,
 	},
 }
+
+func TestDeclaredObjects(t *testing.T) {
+	input := `config_version: 1
+name: pg-01
+type: postgresql
+cluster: prod
+---
+config_version: 2
+object_type: deployment_client
+name: es-01
+type: elastic_search
+---
+config_version: 2
+object_type: deprovision
+name: old-01`
+	expected := []DeclaredDeployment{
+		{Name: "pg-01", Type: "postgresql", Cluster: "prod"},
+		{Name: "es-01", Type: "elastic_search"},
+	}
+	actual, err := declaredObjects([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %+v but saw %+v", expected, actual)
+	}
+	if location := actual[0].Location(); location != "cluster:prod" {
+		t.Errorf("Expected 'cluster:prod' but saw '%s'", location)
+	}
+}
+
+func TestDeclaredEndpointMap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pachelbel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) // #nosec
+	input := `config_version: 2
+object_type: endpoint_map
+endpoint_map:
+  aws-us-east-1-portal.2.dblayer.com: 10.0.0.2
+---
+config_version: 1
+name: pg-01
+type: postgresql
+cluster: prod`
+	if err = ioutil.WriteFile(filepath.Join(dir, "config.yml"), []byte(input), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := DeclaredEndpointMap([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"aws-us-east-1-portal.2.dblayer.com": "10.0.0.2"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %+v but saw %+v", expected, actual)
+	}
+}
+
+func TestIsConfigPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pachelbel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) // #nosec
+	for _, path := range []string{"redis", "pg.yml", "es.YAML", "yaml/nested/pg.yaml", "notes/readme.txt"} {
+		path = filepath.Join(dir, path)
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(path, []byte("config_version: 1\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = os.Mkdir(filepath.Join(dir, "empty"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, test := range []struct {
+		path     string
+		expected bool
+	}{
+		{path: "redis", expected: false},
+		{path: "pg.yml", expected: true},
+		{path: "es.YAML", expected: true},
+		{path: "yaml", expected: true},
+		{path: "notes", expected: false},
+		{path: "empty", expected: false},
+		{path: "missing.yml", expected: false},
+	} {
+		if actual := IsConfigPath(filepath.Join(dir, test.path)); actual != test.expected {
+			t.Errorf("Test #%d: Expected '%s' to be a config path: %v, but saw %v",
+				i, test.path, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// DeclaredDeployment is a deployment named in configuration, as written.
+// Nothing about it has been validated.
+type DeclaredDeployment struct {
+	Name       string
+	Type       string
+	Cluster    string
+	Datacenter string
+	Tags       []string
+}
+
+// Location returns where the deployment is declared to be provisioned
+func (d DeclaredDeployment) Location() string {
+	switch {
+	case len(d.Cluster) > 0:
+		return "cluster:" + d.Cluster
+	case len(d.Datacenter) > 0:
+		return "datacenter:" + d.Datacenter
+	case len(d.Tags) > 0:
+		return "tags:" + strings.Join(d.Tags, ",")
+	default:
+		return ""
+	}
+}
+
+// Declared reads configuration files and directories like ReadFiles, but
+// only returns the deployments they declare, in the order they are
+// declared, without validating them or contacting the Compose API.
+// Deprovision objects are ignored.
+func Declared(paths []string) ([]DeclaredDeployment, error) {
+	declared := []DeclaredDeployment{}
+	err := eachFile(paths, func(data []byte) error {
+		objects, err := declaredObjects(data)
+		declared = append(declared, objects...)
+		return err
+	})
+	return declared, err
+}
+
+// DeclaredEndpointMap reads configuration files and directories like
+// ReadFiles, but only returns the endpoint mappings they declare.
+func DeclaredEndpointMap(paths []string) (map[string]string, error) {
+	cfg := &Config{EndpointMap: make(map[string]string)}
+	err := eachFile(paths, func(data []byte) error {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Split(splitYAMLObjects)
+		for scanner.Scan() {
+			blob := scanner.Bytes()
+			var metadata objectMetadata
+			if err := yaml.Unmarshal(blob, &metadata); err != nil {
+				return err
+			}
+			if metadata.ConfigVersion != 2 || metadata.ObjectType != "endpoint_map" {
+				continue
+			}
+			if err := cfg.readEndpointMapV2(blob); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	})
+	return cfg.EndpointMap, err
+}
+
+// eachFile calls fn with the contents of every file in the provided
+// configuration files and directories
+func eachFile(paths []string, fn func([]byte) error) error {
+	for _, path := range paths {
+		files, err := configFiles(path)
+		if err != nil {
+			return toValidationError(err)
+		}
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file) // #nosec
+			if err != nil {
+				return toValidationError(err)
+			}
+			if err = fn(data); err != nil {
+				return toValidationError(err)
+			}
+		}
+	}
+	return nil
+}
+
+// IsConfigPath reports whether a command line argument names configuration
+// rather than a deployment: a YAML file, or a directory containing at least
+// one. Any other file is not configuration, so a deployment name that
+// happens to match a local file is still read as a name.
+func IsConfigPath(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	} else if !info.IsDir() {
+		return isYAMLFile(path)
+	}
+	files, err := configFiles(path)
+	if err != nil {
+		return false
+	}
+	for _, file := range files {
+		if isYAMLFile(file) {
+			return true
+		}
+	}
+	return false
+}
+
+func isYAMLFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return true
+	}
+	return false
+}
+
+func configFiles(root string) ([]string, error) {
+	files := []string{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if !info.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func declaredObjects(data []byte) ([]DeclaredDeployment, error) {
+	declared := []DeclaredDeployment{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Split(splitYAMLObjects)
+	for scanner.Scan() {
+		blob := scanner.Bytes()
+		var metadata objectMetadata
+		if err := yaml.Unmarshal(blob, &metadata); err != nil {
+			return declared, err
+		}
+		switch {
+		case metadata.ConfigVersion == 1:
+			var d deploymentV1
+			if err := yaml.Unmarshal(blob, &d); err != nil {
+				return declared, err
+			}
+			declared = append(declared, DeclaredDeployment{
+				Name:       d.Name,
+				Type:       d.Type,
+				Cluster:    d.Cluster,
+				Datacenter: d.Datacenter,
+				Tags:       d.Tags,
+			})
+		case metadata.ConfigVersion == 2 && metadata.ObjectType == "deployment_client":
+			var d deploymentClientV2
+			if err := yaml.Unmarshal(blob, &d); err != nil {
+				return declared, err
+			}
+			declared = append(declared, DeclaredDeployment{Name: d.Name, Type: d.Type})
+		}
+	}
+	return declared, scanner.Err()
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError is returned when configuration cannot be read or fails
+// validation. No changes are made to any deployment when it occurs.
+type ValidationError struct {
+	// Deployment is the name of the deployment that failed validation,
+	// if known
+	Deployment string
+	// Kind is the kind of configuration object that failed validation,
+	// e.g. "deployment" or "deprovision"
+	Kind string
+	// Object is the raw configuration object that failed validation, if
+	// the error is specific to a single object
+	Object string
+	// Problems describes everything that is wrong with the configuration
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Object) == 0 {
+		return strings.Join(e.Problems, "\n")
+	}
+	return fmt.Sprintf("Errors occurred while parsing the following %s object:\n%s\nErrors:\n%s",
+		e.Kind, e.Object, strings.Join(e.Problems, "\n"))
+}
+
+func validationError(format string, a ...interface{}) error {
+	return &ValidationError{Problems: []string{fmt.Sprintf(format, a...)}}
+}
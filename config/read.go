@@ -3,7 +3,6 @@ package config
 import (
 	"bufio"
 	"bytes"
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -31,6 +30,18 @@ type Config struct {
 	// private ones.
 	EndpointMap map[string]string
 
+	// Warnings describes any configuration that was valid, but will
+	// be ignored
+	Warnings []string
+
+	// Decisions describes any changes pachelbel decided to make that
+	// are not explicit in the configuration, such as autoscaling
+	Decisions []string
+
+	// Pending lists changes that were found but will not be made, such
+	// as version upgrades outside of a maintenance window
+	Pending []PendingChange
+
 	// Internal fields
 	dNames map[string]struct{}
 }
@@ -61,13 +72,15 @@ func BuildDatacenterFilter(datacenters []string) {
 // data into deployment object. Both configuration files and directories
 // of configuration files are valid arguments, but directories are not
 // read recursively, only immediate child files are parsed.
+//
+// Every error returned is a *ValidationError.
 func ReadFiles(args []string) (*Config, error) {
 	cfg := newConfig()
 
 	for _, path := range args {
 		info, err := os.Stat(path)
 		if err != nil {
-			return cfg, err
+			return cfg, toValidationError(err)
 		}
 		switch mode := info.Mode(); {
 		case mode.IsDir():
@@ -76,16 +89,26 @@ func ReadFiles(args []string) (*Config, error) {
 			err = cfg.readFile(path)
 		}
 		if err != nil {
-			return cfg, err
+			return cfg, toValidationError(err)
 		}
 	}
 	return cfg, nil
 }
 
+func toValidationError(err error) error {
+	if _, ok := err.(*ValidationError); ok {
+		return err
+	}
+	return &ValidationError{Problems: []string{err.Error()}}
+}
+
 func newConfig() *Config {
 	return &Config{
 		Runners:     []runner.Runner{},
 		EndpointMap: make(map[string]string),
+		Warnings:    []string{},
+		Decisions:   []string{},
+		Pending:     []PendingChange{},
 		dNames:      make(map[string]struct{}),
 	}
 }
@@ -152,7 +175,7 @@ func (cfg *Config) readConfig(blob []byte) error {
 	case 2:
 		return cfg.readConfigV2(metadata.ObjectType, blob)
 	default:
-		return fmt.Errorf("Expected `config_version` to be '1' or '2' but saw '%d'",
+		return validationError("Expected `config_version` to be '1' or '2' but saw '%d'",
 			metadata.ConfigVersion)
 
 	}
@@ -167,7 +190,7 @@ func (cfg *Config) readConfigV2(objectType string, blob []byte) error {
 	case "deprovision":
 		return cfg.readDeprovisionV2(blob)
 	default:
-		return fmt.Errorf("'%s' is not a supported object_type", objectType)
+		return validationError("'%s' is not a supported object_type", objectType)
 	}
 }
 
@@ -184,7 +207,7 @@ func (cfg *Config) readDeprovisionV2(blob []byte) error {
 	}
 	name := deprovisioner.Target.GetName()
 	if _, ok := cfg.dNames[name]; ok {
-		return fmt.Errorf("Deployment names must be unique across all configuration objects, but '%s' is specified more than once", name)
+		return validationError("Deployment names must be unique across all configuration objects, but '%s' is specified more than once", name)
 	}
 	cfg.dNames[name] = struct{}{}
 	cfg.Runners = append(cfg.Runners, deprovisioner)
@@ -200,7 +223,7 @@ func (cfg *Config) readDeploymentClientV2(blob []byte) error {
 		return err
 	}
 	if _, ok := cfg.dNames[d.Name]; ok {
-		return fmt.Errorf("Deployment names must be unique, but '%s' is specified more than once",
+		return validationError("Deployment names must be unique, but '%s' is specified more than once",
 			d.Name)
 	}
 	cfg.dNames[d.Name] = struct{}{}
@@ -220,7 +243,7 @@ func (cfg *Config) readEndpointMapV2(blob []byte) error {
 
 	for src, dst := range e.EndpointMap {
 		if existing, ok := cfg.EndpointMap[src]; ok && existing != dst {
-			return fmt.Errorf("Conflicting endpoint mappings:\n%s => %s\nand\n%s => %s", src, existing, src, dst)
+			return validationError("Conflicting endpoint mappings:\n%s => %s\nand\n%s => %s", src, existing, src, dst)
 		}
 		cfg.EndpointMap[src] = dst
 	}
@@ -246,10 +269,15 @@ func (cfg *Config) readConfigV1(blob []byte) error {
 	}
 
 	if _, ok := cfg.dNames[deployment.GetName()]; ok {
-		return fmt.Errorf("Deployment names must be unique, but '%s' is specified more than once",
+		return validationError("Deployment names must be unique, but '%s' is specified more than once",
 			deployment.GetName())
 	}
 	cfg.dNames[deployment.GetName()] = struct{}{}
+	cfg.Warnings = append(cfg.Warnings, deploymentRunner.Target.(deploymentV1).warnings...)
+	cfg.Decisions = append(cfg.Decisions, deploymentRunner.Target.(deploymentV1).decisions...)
+	for _, pending := range deploymentRunner.Target.(deploymentV1).pending {
+		cfg.Pending = append(cfg.Pending, PendingChange{Deployment: deployment.GetName(), Description: pending})
+	}
 	cfg.Runners = append(cfg.Runners, deploymentRunner)
 
 	return nil
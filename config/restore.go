@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+
+	compose "github.com/benjdewan/gocomposeapi"
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/masterminds/semver"
+)
+
+// RestoreFromV1 is the structure corresponding to version 1 of pachelbel's
+// restore_from configuration YAML. New deployments with it are restored
+// from a backup of another deployment instead of being created empty.
+type RestoreFromV1 struct {
+	Deployment string `json:"deployment"`
+	// Backup is a backup ID, or 'latest' for the most recent restorable
+	// backup. It defaults to 'latest'
+	Backup string `json:"backup,omitempty"`
+}
+
+// validateRestoreFromV1 resolves the backup a new deployment is restored
+// from. It must be called after the deployment's version is resolved.
+func validateRestoreFromV1(d *deploymentV1) []string {
+	if d.RestoreFrom == nil {
+		return []string{}
+	} else if len(d.Tags) > 0 {
+		return []string{"Deployments using 'tags' cannot be restored from a backup. Use 'cluster' or 'datacenter' instead"}
+	} else if len(d.RestoreFrom.Deployment) == 0 {
+		return []string{"The 'restore_from.deployment' field is required"}
+	} else if errs := restoreConflictsV1(*d); len(errs) != 0 {
+		return errs
+	}
+
+	source, ok := existingDeployment(d.RestoreFrom.Deployment)
+	if !ok {
+		return []string{fmt.Sprintf("Cannot find the deployment '%s' to restore from", d.RestoreFrom.Deployment)}
+	} else if source.Type != d.Type {
+		return []string{fmt.Sprintf("Cannot restore a %s deployment from a backup of '%s', which is %s",
+			d.Type, source.Name, source.Type)}
+	}
+
+	backups, err := CXN.Backups(source.ID, source.Name)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	backup, err := selectBackup(backups, d.RestoreFrom.Backup)
+	if err != nil {
+		return []string{fmt.Sprintf("Cannot restore from '%s': %v", source.Name, err)}
+	}
+
+	backupVersion := backup.Version
+	if len(backupVersion) == 0 {
+		backupVersion = source.Version
+	}
+	if len(d.Version) == 0 {
+		d.Version = backupVersion
+	} else if err := restorableVersion(backupVersion, d.Version); err != nil {
+		return []string{fmt.Sprintf("Cannot restore backup %s of '%s': %v", backup.ID, source.Name, err)}
+	}
+
+	d.restore = &connection.RestoreSource{DeploymentID: source.ID, BackupID: backup.ID}
+	return []string{}
+}
+
+// restoreConflictsV1 rejects fields that cannot be applied to a deployment
+// restored from a backup, which keeps the storage settings of the backup.
+func restoreConflictsV1(d deploymentV1) []string {
+	errs := []string{}
+	if d.WiredTiger {
+		errs = append(errs, "The 'wired_tiger' field cannot be set alongside 'restore_from'. Restored deployments keep the storage engine of the backup")
+	}
+	if d.CacheMode {
+		errs = append(errs, "The 'cache_mode' field cannot be set alongside 'restore_from'. Restored deployments keep the cache mode of the backup")
+	}
+	return errs
+}
+
+// selectBackup returns the backup with the provided ID, or the most recent
+// restorable backup if the ID is empty or 'latest'
+func selectBackup(backups []compose.Backup, id string) (compose.Backup, error) {
+	if len(id) == 0 || id == "latest" {
+		var latest *compose.Backup
+		for i, backup := range backups {
+			if restorable(backup) && (latest == nil || backup.CreatedAt.After(latest.CreatedAt)) {
+				latest = &backups[i]
+			}
+		}
+		if latest == nil {
+			return compose.Backup{}, fmt.Errorf("it has no restorable backups")
+		}
+		return *latest, nil
+	}
+
+	for _, backup := range backups {
+		if backup.ID != id {
+			continue
+		} else if !restorable(backup) {
+			return backup, fmt.Errorf("backup %s is %s and cannot be restored", id, backup.Status)
+		}
+		return backup, nil
+	}
+	return compose.Backup{}, fmt.Errorf("backup %s does not exist", id)
+}
+
+func restorable(backup compose.Backup) bool {
+	return backup.IsRestorable && backup.Status == "complete"
+}
+
+// restorableVersion returns an error unless a backup taken at one version
+// can be restored into a deployment of another: the same major version and
+// no older. Unparseable versions are not checked.
+func restorableVersion(backup, target string) error {
+	backupVersion, err := semver.NewVersion(backup)
+	if err != nil {
+		return nil
+	}
+	targetVersion, err := semver.NewVersion(target)
+	if err != nil {
+		return nil
+	}
+	if backupVersion.Major() != targetVersion.Major() || targetVersion.LessThan(backupVersion) {
+		return fmt.Errorf("it was taken at version %s, which cannot be restored into version %s", backup, target)
+	}
+	return nil
+}
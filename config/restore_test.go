@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+var testBackups = []compose.Backup{
+	{ID: "old", Status: "complete", IsRestorable: true, CreatedAt: time.Date(2017, 9, 1, 0, 0, 0, 0, time.UTC)},
+	{ID: "new", Status: "complete", IsRestorable: true, CreatedAt: time.Date(2017, 9, 3, 0, 0, 0, 0, time.UTC)},
+	{ID: "running", Status: "running", IsRestorable: true, CreatedAt: time.Date(2017, 9, 4, 0, 0, 0, 0, time.UTC)},
+}
+
+var selectBackupTests = []struct {
+	id       string
+	expected string
+	valid    bool
+}{
+	{id: "", expected: "new", valid: true},
+	{id: "latest", expected: "new", valid: true},
+	{id: "old", expected: "old", valid: true},
+	{id: "running", valid: false},
+	{id: "missing", valid: false},
+}
+
+func TestSelectBackup(t *testing.T) {
+	for i, test := range selectBackupTests {
+		backup, err := selectBackup(testBackups, test.id)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("Test #%d: Expected selecting '%s' to fail", i, test.id)
+			}
+			continue
+		}
+		if err != nil || backup.ID != test.expected {
+			t.Errorf("Test #%d: Expected backup '%s' but saw '%s', %v", i, test.expected, backup.ID, err)
+		}
+	}
+}
+
+var restorableVersionTests = []struct {
+	backup string
+	target string
+	valid  bool
+}{
+	{backup: "9.6.3", target: "9.6.3", valid: true},
+	{backup: "9.6.3", target: "9.6.5", valid: true},
+	{backup: "9.6.5", target: "9.6.3", valid: false},
+	{backup: "9.6.3", target: "10.0.1", valid: false},
+	{backup: "unknown", target: "10.0.1", valid: true},
+}
+
+func TestRestorableVersion(t *testing.T) {
+	for i, test := range restorableVersionTests {
+		err := restorableVersion(test.backup, test.target)
+		if test.valid && err != nil {
+			t.Errorf("Test #%d: Expected %s to restore into %s, but saw: %v", i, test.backup, test.target, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("Test #%d: Expected %s not to restore into %s", i, test.backup, test.target)
+		}
+	}
+}
+
+var restoreConflictsV1Tests = []struct {
+	config deploymentV1
+	errs   int
+}{
+	{config: deploymentV1{Type: "postgresql", Scaling: 3, Notes: "restored"}, errs: 0},
+	{config: deploymentV1{Type: "mongodb", WiredTiger: true}, errs: 1},
+	{config: deploymentV1{Type: "redis", CacheMode: true}, errs: 1},
+}
+
+func TestRestoreConflictsV1(t *testing.T) {
+	for i, test := range restoreConflictsV1Tests {
+		if errs := restoreConflictsV1(test.config); len(errs) != test.errs {
+			t.Errorf("Test #%d: Expected %d error(s) but saw %v", i, test.errs, errs)
+		}
+	}
+}
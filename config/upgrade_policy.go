@@ -0,0 +1,210 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/runner"
+	"github.com/masterminds/semver"
+)
+
+// Kinds of version upgrade
+const (
+	upgradePatch = "patch"
+	upgradeMinor = "minor"
+	upgradeMajor = "major"
+)
+
+// now is replaced in tests
+var now = time.Now
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// UpgradePolicyV1 is the structure corresponding to version 1 of pachelbel's
+// upgrade_policy configuration YAML. Setting it enables in-place upgrades,
+// subject to its restrictions.
+type UpgradePolicyV1 struct {
+	// Allow lists the kinds of upgrade that may be made. Patch and minor
+	// upgrades are allowed if it is empty.
+	Allow             []string             `json:"allow,omitempty"`
+	MaintenanceWindow *MaintenanceWindowV1 `json:"maintenance_window,omitempty"`
+	ReportOnly        bool                 `json:"report_only,omitempty"`
+}
+
+// MaintenanceWindowV1 is the period, in UTC, during which upgrades may start.
+// If EndHour is not after StartHour the window runs past midnight.
+type MaintenanceWindowV1 struct {
+	// Days are the three letter names of the days the window opens on.
+	// The window opens every day if it is empty.
+	Days      []string `json:"days,omitempty"`
+	StartHour int      `json:"start_hour"`
+	EndHour   int      `json:"end_hour"`
+}
+
+// PendingChange is a change pachelbel found but did not make
+type PendingChange struct {
+	Deployment  string
+	Description string
+}
+
+func validateUpgradePolicyV1(policy *UpgradePolicyV1) []string {
+	if policy == nil {
+		return []string{}
+	}
+
+	errs := []string{}
+	for _, kind := range policy.Allow {
+		switch kind {
+		case upgradePatch, upgradeMinor, upgradeMajor:
+		default:
+			errs = append(errs, fmt.Sprintf("'%s' is not a valid upgrade kind. Expected '%s', '%s' or '%s'",
+				kind, upgradePatch, upgradeMinor, upgradeMajor))
+		}
+	}
+
+	window := policy.MaintenanceWindow
+	if window == nil {
+		return errs
+	}
+	for _, day := range window.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			errs = append(errs, fmt.Sprintf("'%s' is not a valid maintenance window day. Expected one of mon, tue, wed, thu, fri, sat or sun", day))
+		}
+	}
+	if window.StartHour < 0 || window.StartHour > 23 {
+		errs = append(errs, "The 'maintenance_window.start_hour' field must be between 0 and 23")
+	}
+	if window.EndHour < 0 || window.EndHour > 24 {
+		errs = append(errs, "The 'maintenance_window.end_hour' field must be between 0 and 24")
+	}
+	return errs
+}
+
+// upgradeV1 decides whether an existing deployment should be upgraded.
+// Upgrades that are available but not allowed are recorded as pending.
+func upgradeV1(d *deploymentV1, existing connection.ExistingDeployment) ([]string, []string) {
+	if d.UpgradePolicy == nil {
+		if errs := validateVersionUpgradeV1(d, existing.Upgrades); len(errs) != 0 {
+			return []string{runner.ActionUpgrade}, errs
+		} else if !d.Upgradeable {
+			d.pendingUpgrade("'upgradeable' is not set")
+			return []string{}, []string{}
+		}
+		return []string{runner.ActionUpgrade}, []string{}
+	}
+
+	policy := d.UpgradePolicy
+	requested := d.Version
+	if errs := validateVersionUpgradeV1(d, policy.allowed(existing.Version, existing.Upgrades)); len(errs) != 0 {
+		d.Version = requested
+		if len(validateVersionUpgradeV1(d, existing.Upgrades)) != 0 {
+			return []string{runner.ActionUpgrade}, errs
+		}
+		d.pendingUpgrade(fmt.Sprintf("%s upgrades are not allowed by the upgrade_policy",
+			upgradeKind(existing.Version, d.Version)))
+		return []string{}, []string{}
+	}
+
+	if policy.ReportOnly {
+		d.pendingUpgrade("the upgrade_policy is report_only")
+		return []string{}, []string{}
+	} else if window := policy.MaintenanceWindow; window != nil && !window.open(now().UTC()) {
+		d.pendingUpgrade(fmt.Sprintf("it is outside the maintenance window (%s)", window))
+		return []string{}, []string{}
+	}
+	return []string{runner.ActionUpgrade}, []string{}
+}
+
+func (d *deploymentV1) pendingUpgrade(reason string) {
+	d.pending = append(d.pending, fmt.Sprintf("Upgrade to %s is pending: %s", d.Version, reason))
+	d.Version = ""
+}
+
+// allowed returns the upgrades from the current version of the kinds the
+// policy allows
+func (policy UpgradePolicyV1) allowed(current string, upgrades []*semver.Version) []*semver.Version {
+	kinds := map[string]struct{}{}
+	for _, kind := range policy.Allow {
+		kinds[kind] = struct{}{}
+	}
+	if len(kinds) == 0 {
+		kinds = map[string]struct{}{upgradePatch: {}, upgradeMinor: {}}
+	}
+
+	allowed := []*semver.Version{}
+	for _, upgrade := range upgrades {
+		if _, ok := kinds[upgradeKind(current, upgrade.String())]; ok {
+			allowed = append(allowed, upgrade)
+		}
+	}
+	return allowed
+}
+
+// upgradeKind returns whether upgrading from one version to another is a
+// major, minor or patch upgrade. Unparseable versions are treated as major.
+func upgradeKind(from, to string) string {
+	fromVersion, err := semver.NewVersion(from)
+	if err != nil {
+		return upgradeMajor
+	}
+	toVersion, err := semver.NewVersion(to)
+	if err != nil {
+		return upgradeMajor
+	}
+	switch {
+	case fromVersion.Major() != toVersion.Major():
+		return upgradeMajor
+	case fromVersion.Minor() != toVersion.Minor():
+		return upgradeMinor
+	default:
+		return upgradePatch
+	}
+}
+
+// open reports whether the maintenance window is open at the provided time,
+// which must be in UTC
+func (w MaintenanceWindowV1) open(t time.Time) bool {
+	hour := t.Hour()
+	day := t.Weekday()
+	if w.EndHour <= w.StartHour {
+		// The window runs past midnight. Early hours belong to the
+		// window that opened the day before.
+		if hour < w.EndHour {
+			return w.onDay((day + 6) % 7)
+		} else if hour < w.StartHour {
+			return false
+		}
+		return w.onDay(day)
+	}
+	return hour >= w.StartHour && hour < w.EndHour && w.onDay(day)
+}
+
+func (w MaintenanceWindowV1) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, name := range w.Days {
+		if weekdays[strings.ToLower(name)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (w MaintenanceWindowV1) String() string {
+	days := "daily"
+	if len(w.Days) > 0 {
+		days = strings.Join(w.Days, ", ")
+	}
+	return fmt.Sprintf("%s, %02d:00-%02d:00 UTC", days, w.StartHour, w.EndHour)
+}
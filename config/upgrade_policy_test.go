@@ -0,0 +1,96 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/masterminds/semver"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	for i, test := range maintenanceWindowTests {
+		at, err := time.Parse(time.RFC3339, test.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := test.window.open(at); actual != test.open {
+			t.Errorf("Test #%d: Expected open(%s) to be %t for %s", i, test.at, test.open, test.window)
+		}
+	}
+}
+
+// 2017-09-02 is a Saturday
+var maintenanceWindowTests = []struct {
+	window MaintenanceWindowV1
+	at     string
+	open   bool
+}{
+	{MaintenanceWindowV1{StartHour: 2, EndHour: 6}, "2017-09-02T02:00:00Z", true},
+	{MaintenanceWindowV1{StartHour: 2, EndHour: 6}, "2017-09-02T06:00:00Z", false},
+	{MaintenanceWindowV1{Days: []string{"sat"}, StartHour: 2, EndHour: 6}, "2017-09-02T03:30:00Z", true},
+	{MaintenanceWindowV1{Days: []string{"Sun"}, StartHour: 2, EndHour: 6}, "2017-09-02T03:30:00Z", false},
+	// Windows that run past midnight belong to the day they open on
+	{MaintenanceWindowV1{Days: []string{"fri"}, StartHour: 22, EndHour: 2}, "2017-09-02T01:00:00Z", true},
+	{MaintenanceWindowV1{Days: []string{"fri"}, StartHour: 22, EndHour: 2}, "2017-09-02T23:00:00Z", false},
+	{MaintenanceWindowV1{Days: []string{"sat"}, StartHour: 22, EndHour: 2}, "2017-09-02T23:00:00Z", true},
+	{MaintenanceWindowV1{StartHour: 22, EndHour: 2}, "2017-09-02T12:00:00Z", false},
+}
+
+func TestUpgradeV1(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2017, time.September, 2, 12, 0, 0, 0, time.UTC)
+	}
+	defer func() { now = time.Now }()
+
+	for i, test := range upgradeV1Tests {
+		existing := connection.ExistingDeployment{
+			Version:  "9.6.3",
+			Upgrades: []*semver.Version{semver.MustParse("9.6.5"), semver.MustParse("10.0.1")},
+		}
+		d := test.config
+		actions, errs := upgradeV1(&d, existing)
+		if len(errs) != 0 {
+			t.Errorf("Test #%d: Unexpected errors: %v", i, errs)
+		}
+		if d.Version != test.version || (len(actions) == 1) != (len(test.version) > 0) {
+			t.Errorf("Test #%d: Expected to upgrade to '%s', but saw '%s' and actions %v", i, test.version, d.Version, actions)
+		}
+		if len(d.pending) != test.pending {
+			t.Errorf("Test #%d: Expected %d pending upgrade(s) but saw %v", i, test.pending, d.pending)
+		}
+	}
+}
+
+var upgradeV1Tests = []struct {
+	config  deploymentV1
+	version string
+	pending int
+}{
+	{config: deploymentV1{Version: ">=9.6.4"}, version: "", pending: 1},
+	{config: deploymentV1{Version: ">=9.6.4", Upgradeable: true}, version: "10.0.1", pending: 0},
+	// Patch and minor upgrades are allowed by default
+	{config: deploymentV1{Version: ">=9.6.4", UpgradePolicy: &UpgradePolicyV1{}}, version: "9.6.5", pending: 0},
+	{
+		config:  deploymentV1{Version: ">=10", UpgradePolicy: &UpgradePolicyV1{Allow: []string{"patch"}}},
+		version: "",
+		pending: 1,
+	},
+	{
+		config:  deploymentV1{Version: ">=10", UpgradePolicy: &UpgradePolicyV1{Allow: []string{"major"}}},
+		version: "10.0.1",
+		pending: 0,
+	},
+	{
+		config:  deploymentV1{Version: ">=9.6.4", UpgradePolicy: &UpgradePolicyV1{ReportOnly: true}},
+		version: "",
+		pending: 1,
+	},
+	{
+		config: deploymentV1{Version: ">=9.6.4", UpgradePolicy: &UpgradePolicyV1{
+			MaintenanceWindow: &MaintenanceWindowV1{Days: []string{"sun"}, StartHour: 2, EndHour: 6},
+		}},
+		version: "",
+		pending: 1,
+	},
+}
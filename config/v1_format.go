@@ -1,27 +1,39 @@
 package config
 
+import "github.com/benjdewan/pachelbel/connection"
+
 // deploymentV1 is the structure corresponding to version 1 of
 // pachelbel's configuration YAML
 // codebeat:disable[TOO_MANY_IVARS]
 type deploymentV1 struct {
-	ConfigVersion int         `json:"config_version"`
-	Version       string      `json:"version"`
-	Type          string      `json:"type"`
-	Cluster       string      `json:"cluster"`
-	Datacenter    string      `json:"datacenter"`
-	Tags          []string    `json:"tags"`
-	Name          string      `json:"name"`
-	Notes         string      `json:"notes"`
-	SSL           bool        `json:"ssl"`
-	Teams         [](*TeamV1) `json:"teams"`
-	Scaling       int         `json:"scaling"`
-	WiredTiger    bool        `json:"wired_tiger"`
-	CacheMode     bool        `json:"cache_mode"`
-	Timeout       *int        `json:"timeout,omitempty"`
-	Upgradeable   bool        `json:"upgradeable,omitempty"`
+	ConfigVersion       int                `json:"config_version"`
+	Version             string             `json:"version"`
+	Type                string             `json:"type"`
+	Cluster             string             `json:"cluster"`
+	Datacenter          string             `json:"datacenter"`
+	Tags                []string           `json:"tags"`
+	Name                string             `json:"name"`
+	Notes               string             `json:"notes"`
+	SSL                 bool               `json:"ssl"`
+	Teams               [](*TeamV1)        `json:"teams"`
+	Scaling             int                `json:"scaling"`
+	WiredTiger          bool               `json:"wired_tiger"`
+	CacheMode           bool               `json:"cache_mode"`
+	Timeout             *int               `json:"timeout,omitempty"`
+	Upgradeable         bool               `json:"upgradeable,omitempty"`
+	AllowScaleDown      bool               `json:"allow_scale_down,omitempty"`
+	Autoscale           *AutoscaleV1       `json:"autoscale,omitempty"`
+	UpgradePolicy       *UpgradePolicyV1   `json:"upgrade_policy,omitempty"`
+	Whitelist           []WhitelistEntryV1 `json:"whitelist"`
+	BackupBeforeUpgrade bool               `json:"backup_before_upgrade,omitempty"`
+	RestoreFrom         *RestoreFromV1     `json:"restore_from,omitempty"`
 
 	//internal
-	id string
+	id        string
+	warnings  []string
+	decisions []string
+	pending   []string
+	restore   *connection.RestoreSource
 }
 
 // codebeat:enable[TOO_MANY_IVARS]
@@ -85,11 +97,11 @@ func (d deploymentV1) GetVersion() string {
 	return d.Version
 }
 
-// GetScaling returns the database scaling value for the deployment, or 1
-// if it does not exist.
+// GetScaling returns the database scaling value for the deployment, or 0
+// if it is not set or an existing deployment should not be rescaled.
 func (d deploymentV1) GetScaling() int {
-	if d.Scaling <= 1 {
-		return 1
+	if d.Scaling < 0 {
+		return 0
 	}
 	return d.Scaling
 }
@@ -115,6 +127,34 @@ func (d deploymentV1) GetCacheMode() bool {
 	return d.Type == "redis" && d.CacheMode
 }
 
+// GetWhitelist returns the CIDR ranges that may connect to the deployment,
+// or nil if its whitelist is not managed by pachelbel.
+func (d deploymentV1) GetWhitelist() []connection.WhitelistEntry {
+	if d.Whitelist == nil {
+		return nil
+	}
+	entries := []connection.WhitelistEntry{}
+	for _, entry := range d.Whitelist {
+		entries = append(entries, connection.WhitelistEntry{
+			CIDR:        entry.CIDR,
+			Description: entry.Description,
+		})
+	}
+	return entries
+}
+
+// GetBackupBeforeUpgrade is true if an on-demand backup should be taken
+// before upgrading the deployment
+func (d deploymentV1) GetBackupBeforeUpgrade() bool {
+	return d.BackupBeforeUpgrade
+}
+
+// GetRestoreSource returns the backup to restore a new deployment from, or
+// nil if it should be created empty
+func (d deploymentV1) GetRestoreSource() *connection.RestoreSource {
+	return d.restore
+}
+
 // GetSSL returns true if SSL should be enabled for a deployment.
 func (d deploymentV1) GetSSL() bool {
 	return d.SSL
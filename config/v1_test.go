@@ -2,6 +2,8 @@ package config
 
 import (
 	"testing"
+
+	"github.com/benjdewan/pachelbel/connection"
 )
 
 func TestValidateV1(t *testing.T) {
@@ -20,6 +22,21 @@ func TestValidateV1(t *testing.T) {
 	}
 }
 
+func TestValidateExistingScalingV1(t *testing.T) {
+	existing := connection.ExistingDeployment{Scaling: 10, UtilizedScaling: 5}
+	for i, test := range existingScalingV1Tests {
+		d := deploymentV1{Name: "pg-01", Scaling: test.scaling, AllowScaleDown: test.allowScaleDown}
+		actions, errs := validateExistingScalingV1(&d, existing)
+		if len(actions) != test.actions || len(errs) != test.errs || len(d.warnings) != test.warnings {
+			t.Errorf("Test #%d: Expected %d action(s), %d error(s) and %d warning(s) but saw %v, %v and %v",
+				i, test.actions, test.errs, test.warnings, actions, errs, d.warnings)
+		}
+		if d.GetScaling() != test.units {
+			t.Errorf("Test #%d: Expected to scale to %d units but saw %d", i, test.units, d.GetScaling())
+		}
+	}
+}
+
 func TestXOR(t *testing.T) {
 	for _, test := range xorTests {
 		actual := xor(test.a, test.b)
@@ -72,6 +89,27 @@ var (
 	invalidScaling = -1
 )
 
+var existingScalingV1Tests = []struct {
+	scaling        int
+	allowScaleDown bool
+	actions        int
+	errs           int
+	warnings       int
+	units          int
+}{
+	// Unset or unchanged scaling is a no-op
+	{scaling: 0, actions: 0, errs: 0, warnings: 0, units: 0},
+	{scaling: 10, actions: 0, errs: 0, warnings: 0, units: 0},
+	// Scaling up never needs permission
+	{scaling: 12, actions: 1, errs: 0, warnings: 0, units: 12},
+	// Scaling down without permission is ignored with a warning
+	{scaling: 6, actions: 0, errs: 0, warnings: 1, units: 0},
+	// 5 utilized units with 20% headroom leaves a minimum of 6
+	{scaling: 6, allowScaleDown: true, actions: 1, errs: 0, warnings: 0, units: 6},
+	{scaling: 5, allowScaleDown: true, actions: 0, errs: 1, warnings: 0, units: 5},
+	{scaling: -1, actions: 0, errs: 1, warnings: 0, units: 0},
+}
+
 var configValidateV1Tests = []struct {
 	config deploymentV1
 	valid  bool
@@ -316,3 +354,40 @@ var configValidateV1Tests = []struct {
 		valid: true,
 	},
 }
+
+func TestValidateExistingNotesV1(t *testing.T) {
+	for i, test := range existingNotesV1Tests {
+		d := deploymentV1{Name: "pg-01", Notes: test.declared}
+		actions := validateExistingNotesV1(&d, connection.ExistingDeployment{Notes: test.existing})
+		if len(actions) != test.actions || len(d.warnings) != test.warnings {
+			t.Errorf("Test #%d: Expected %d action(s) and %d warning(s) but saw %v and %v",
+				i, test.actions, test.warnings, actions, d.warnings)
+		} else if d.Notes != test.notes {
+			t.Errorf("Test #%d: Expected notes %q but saw %q", i, test.notes, d.Notes)
+		}
+	}
+}
+
+var existingNotesV1Tests = []struct {
+	existing string
+	declared string
+	notes    string
+	actions  int
+	warnings int
+}{
+	{existing: "owner: search", declared: "", notes: "", actions: 0},
+	{existing: "owner: search", declared: "owner: search", notes: "", actions: 0},
+	{existing: "owner: search", declared: "owner: billing", notes: "owner: billing", actions: 1},
+	// Deployments pending deletion keep their deletion marker
+	{
+		existing: "owner: search\n[pachelbel] pending deletion, expires 2017-10-03T12:00:00Z",
+		declared: "owner: search",
+		notes:    "", actions: 0, warnings: 1,
+	},
+	{
+		existing: "owner: search\n[pachelbel] pending deletion, expires 2017-10-03T12:00:00Z",
+		declared: "owner: billing",
+		notes:    "owner: billing\n[pachelbel] pending deletion, expires 2017-10-03T12:00:00Z",
+		actions:  1, warnings: 1,
+	},
+}
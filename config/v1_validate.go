@@ -2,9 +2,9 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/benjdewan/pachelbel/connection"
 	"github.com/benjdewan/pachelbel/runner"
@@ -22,6 +22,8 @@ func validateV1(d deploymentV1, input string) (runner.Runner, error) {
 	errs = append(errs, validateDatacenterV1(d)...)
 	errs = append(errs, validateWiredTiger(d.WiredTiger, d.Type)...)
 	errs = append(errs, validateCacheMode(d.CacheMode, d.Type)...)
+	errs = append(errs, validateUpgradePolicyV1(d.UpgradePolicy)...)
+	errs = append(errs, validateWhitelistV1(d.Whitelist)...)
 
 	if existing, ok := existingDeployment(d.Name); ok {
 		return validateExistingV1(&d, existing, input, errs)
@@ -29,6 +31,14 @@ func validateV1(d deploymentV1, input string) (runner.Runner, error) {
 
 	errs = append(errs, validateVersionByTypeV1(&d)...)
 	errs = append(errs, validateScaling(d.Scaling)...)
+	if len(errs) == 0 {
+		errs = append(errs, validateRestoreFromV1(&d)...)
+	}
+	if aErrs := validateAutoscaleV1(&d); len(aErrs) != 0 {
+		errs = append(errs, aErrs...)
+	} else if d.Autoscale != nil {
+		d.Scaling = d.Autoscale.Min
+	}
 
 	deploymentRunner := runner.Runner{
 		Target: runner.Accessor(d),
@@ -39,33 +49,79 @@ func validateV1(d deploymentV1, input string) (runner.Runner, error) {
 		return deploymentRunner, nil
 	}
 
-	return deploymentRunner, fmt.Errorf("Errors occurred while parsing the following deployment object:\n%s\nErrors:\n%s",
-		input, strings.Join(errs, "\n"))
+	return deploymentRunner, &ValidationError{
+		Deployment: d.Name,
+		Kind:       "deployment",
+		Object:     input,
+		Problems:   errs,
+	}
 }
 
-func validateExistingScalingV1(d *deploymentV1, existing connection.ExistingDeployment, errs []string) ([]string, []string) {
-	actions := []string{}
-	if d.Scaling == 0 || d.Scaling == existing.Scaling {
+func validateExistingScalingV1(d *deploymentV1, existing connection.ExistingDeployment) ([]string, []string) {
+	if d.Autoscale != nil {
+		return autoscaleV1(d, existing)
+	}
+	if errs := validateScaling(d.Scaling); len(errs) != 0 {
+		return []string{}, errs
+	}
+
+	switch {
+	case d.Scaling == 0 || d.Scaling == existing.Scaling:
 		d.Scaling = 0
-	} else if d.Scaling < existing.UtilizedScaling {
+	case d.Scaling > existing.Scaling:
+		return []string{runner.ActionResize}, []string{}
+	case !d.AllowScaleDown:
+		d.warnings = append(d.warnings, fmt.Sprintf(
+			"'%s' is scaled to %d units, but only %d are specified. Ignoring specified units. Set 'allow_scale_down: true' to scale it down",
+			d.Name, existing.Scaling, d.Scaling))
 		d.Scaling = 0
-		_, err := fmt.Fprintf(os.Stderr,
-			"WARNING: %s currently utilizes %d units, but only %d are specified. Ignoring specified units",
-			d.Name, existing.UtilizedScaling, d.Scaling)
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("Internal error while rendering configuration: %v", err))
+	default:
+		if minimum := minimumUnits(existing.UtilizedScaling); d.Scaling < minimum {
+			return []string{}, []string{fmt.Sprintf(
+				"Cannot scale '%s' down to %d units. It currently utilizes %d units, so with %d%% headroom it needs at least %d",
+				d.Name, d.Scaling, existing.UtilizedScaling, ScaleDownHeadroom, minimum)}
 		}
-	} else {
-		actions = append(actions, runner.ActionResize)
-		errs = append(errs, validateScaling(d.Scaling)...)
+		return []string{runner.ActionResize}, []string{}
+	}
+	return []string{}, []string{}
+}
+
+// validateExistingNotesV1 only replaces the notes of an existing deployment
+// if they differ from the declared notes. A deployment pending deletion
+// keeps its deletion marker, so applying declared notes does not cancel
+// its deletion.
+func validateExistingNotesV1(d *deploymentV1, existing connection.ExistingDeployment) []string {
+	if len(d.Notes) == 0 {
+		return []string{}
 	}
-	return actions, errs
+	if expiry, ok := connection.DeletionExpiry(existing.Notes); ok {
+		d.Notes = connection.MarkForDeletion(d.Notes, expiry)
+		d.warnings = append(d.warnings, fmt.Sprintf(
+			"'%s' is pending deletion until %s. Its deletion marker is kept in its notes. Use 'pachelbel undelete' to cancel the deletion",
+			d.Name, expiry.Format(time.RFC3339)))
+	}
+	if d.Notes == existing.Notes {
+		d.Notes = ""
+		return []string{}
+	}
+	return []string{runner.ActionComment}
+}
+
+// minimumUnits is the fewest units a deployment utilizing the provided
+// number of units can be scaled down to while leaving ScaleDownHeadroom
+// percent of room to grow.
+func minimumUnits(utilized int) int {
+	minimum := (utilized*(100+ScaleDownHeadroom) + 99) / 100
+	if minimum < 1 {
+		return 1
+	}
+	return minimum
 }
 
 func validateExistingV1(d *deploymentV1, existing connection.ExistingDeployment, input string, errs []string) (runner.Runner, error) {
 	d.id = existing.ID
 
-	actions, sErrs := validateExistingScalingV1(d, existing, errs)
+	actions, sErrs := validateExistingScalingV1(d, existing)
 	errs = append(errs, sErrs...)
 
 	if d.Version == existing.Version {
@@ -73,20 +129,17 @@ func validateExistingV1(d *deploymentV1, existing connection.ExistingDeployment,
 	} else if versionEquivalence(d.Version, existing.Version) {
 		d.Version = ""
 	} else {
-		vErrs := validateVersionUpgradeV1(d, existing.Upgrades)
-		if len(vErrs) == 0 && !d.Upgradeable {
-			fmt.Printf("A version upgrade for '%s' exists: '%s'\n", d.Name, d.Version)
-			d.Version = ""
-		} else {
-			actions = append(actions, runner.ActionUpgrade)
-		}
-		errs = append(errs, vErrs...)
+		uActions, uErrs := upgradeV1(d, existing)
+		actions = append(actions, uActions...)
+		errs = append(errs, uErrs...)
 	}
 
-	if d.Notes == existing.Notes {
-		d.Notes = ""
-	} else if d.Notes != "" {
-		actions = append(errs, runner.ActionComment)
+	actions = append(actions, validateExistingNotesV1(d, existing)...)
+
+	if len(errs) == 0 {
+		wActions, wErrs := whitelistV1(d, existing)
+		actions = append(actions, wActions...)
+		errs = append(errs, wErrs...)
 	}
 	action, runFunc := toAction(actions)
 	deploymentRunner := runner.Runner{
@@ -98,8 +151,12 @@ func validateExistingV1(d *deploymentV1, existing connection.ExistingDeployment,
 		return deploymentRunner, nil
 	}
 
-	return deploymentRunner, fmt.Errorf("Errors occurred while parsing the following deployment object:\n%s\nErrors:\n%s",
-		input, strings.Join(errs, "\n"))
+	return deploymentRunner, &ValidationError{
+		Deployment: d.Name,
+		Kind:       "deployment",
+		Object:     input,
+		Problems:   errs,
+	}
 }
 
 func versionEquivalence(requested, existing string) bool {
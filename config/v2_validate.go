@@ -1,11 +1,6 @@
 package config
 
-import (
-	"fmt"
-	"strings"
-
-	"github.com/benjdewan/pachelbel/runner"
-)
+import "github.com/benjdewan/pachelbel/runner"
 
 func validateDeploymentClientV2(d deploymentClientV2, input string) error {
 	errs := []string{}
@@ -16,8 +11,12 @@ func validateDeploymentClientV2(d deploymentClientV2, input string) error {
 		return nil
 	}
 
-	return fmt.Errorf("Errors occurred while parsing the following deployment object:\n%s\nErrors:\n%s",
-		input, strings.Join(errs, "\n"))
+	return &ValidationError{
+		Deployment: d.Name,
+		Kind:       "deployment",
+		Object:     input,
+		Problems:   errs,
+	}
 }
 
 func validateDeprovisionV2(d deprovisionObjectV2, input string) (runner.Runner, bool, error) {
@@ -38,7 +37,12 @@ func validateDeprovisionV2(d deprovisionObjectV2, input string) (runner.Runner,
 		return deprovisioner, skip, nil
 	}
 
-	return deprovisioner, false, fmt.Errorf("Errors occurred while parsing the following deprovision object:\n%s\nErrors:\n%s", input, strings.Join(errs, "\n"))
+	return deprovisioner, false, &ValidationError{
+		Deployment: d.Name,
+		Kind:       "deprovision",
+		Object:     input,
+		Problems:   errs,
+	}
 }
 
 func validateDeprovisionByIDV2(d deprovisionObjectV2) (runner.Runner, bool) {
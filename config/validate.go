@@ -24,6 +24,9 @@ var (
 	Datacenters map[string]struct{}
 	// CXN is a connection object for retrieving existing deployment information
 	CXN *connection.Connection
+	// ScaleDownHeadroom is the percentage of the units an existing
+	// deployment utilizes that must remain free after scaling it down
+	ScaleDownHeadroom = 20
 )
 
 func existingDeployment(idOrName string) (connection.ExistingDeployment, bool) {
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/benjdewan/pachelbel/connection"
+	"github.com/benjdewan/pachelbel/runner"
+)
+
+// WhitelistEntryV1 is the structure corresponding to version 1 of
+// pachelbel's whitelist configuration YAML
+type WhitelistEntryV1 struct {
+	CIDR        string `json:"cidr"`
+	Description string `json:"description"`
+}
+
+func validateWhitelistV1(whitelist []WhitelistEntryV1) []string {
+	errs := []string{}
+	seen := make(map[string]struct{})
+	for _, entry := range whitelist {
+		_, ipNet, err := net.ParseCIDR(entry.CIDR)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("'%s' is not a valid CIDR range, e.g. '10.0.0.0/24'", entry.CIDR))
+			continue
+		}
+		if _, ok := seen[ipNet.String()]; ok {
+			errs = append(errs, fmt.Sprintf("'%s' is whitelisted more than once", entry.CIDR))
+		}
+		seen[ipNet.String()] = struct{}{}
+	}
+	return errs
+}
+
+// whitelistV1 compares the whitelist of an existing deployment to the
+// configured one and records any changes that will be made.
+func whitelistV1(d *deploymentV1, existing connection.ExistingDeployment) ([]string, []string) {
+	if d.Whitelist == nil {
+		return []string{}, []string{}
+	} else if CXN == nil {
+		return []string{runner.ActionWhitelist}, []string{}
+	}
+
+	current, err := CXN.Whitelist(existing.ID, existing.Name)
+	if err != nil {
+		return []string{}, []string{err.Error()}
+	}
+	add, remove := connection.DiffWhitelist(d.GetWhitelist(), current)
+	if len(add) == 0 && len(remove) == 0 {
+		d.Whitelist = nil
+		return []string{}, []string{}
+	}
+
+	changes := []string{}
+	for _, entry := range add {
+		changes = append(changes, fmt.Sprintf("adding %s (%s)", entry.CIDR, entry.Description))
+	}
+	for _, entry := range remove {
+		changes = append(changes, fmt.Sprintf("removing %s", entry.CIDR))
+	}
+	d.decisions = append(d.decisions, fmt.Sprintf("Whitelisting '%s': %s", d.Name, strings.Join(changes, ", ")))
+	return []string{runner.ActionWhitelist}, []string{}
+}
@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestValidateWhitelistV1(t *testing.T) {
+	for i, test := range validateWhitelistV1Tests {
+		errs := validateWhitelistV1(test.whitelist)
+		if len(errs) != test.errs {
+			t.Errorf("Test #%d: Expected %d error(s) but saw %v", i, test.errs, errs)
+		}
+	}
+}
+
+var validateWhitelistV1Tests = []struct {
+	whitelist []WhitelistEntryV1
+	errs      int
+}{
+	{whitelist: nil, errs: 0},
+	{whitelist: []WhitelistEntryV1{}, errs: 0},
+	{whitelist: []WhitelistEntryV1{{CIDR: "10.0.0.0/24"}, {CIDR: "2001:db8::/32"}}, errs: 0},
+	{whitelist: []WhitelistEntryV1{{CIDR: "10.0.0.1"}}, errs: 1},
+	{whitelist: []WhitelistEntryV1{{CIDR: "10.0.0.0/33"}}, errs: 1},
+	{whitelist: []WhitelistEntryV1{{CIDR: "10.0.0.0/24"}, {CIDR: "10.0.0.1/24"}}, errs: 1},
+}
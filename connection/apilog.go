@@ -0,0 +1,132 @@
+package connection
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+const (
+	// LogFormatText logs the raw requests and responses made by gocomposeapi
+	LogFormatText = "text"
+	// LogFormatJSON logs one JSON object per Compose API call
+	LogFormatJSON = "json"
+)
+
+// apiRecord is a single line of the JSON API log. StatusCode is that of the
+// last response the call received, and is omitted if there was none.
+// Attempt counts the polls of a recipe's status and the retries of a
+// deployment lookup; other calls are never repeated, so it is 1 for them.
+// codebeat:disable[TOO_MANY_IVARS]
+type apiRecord struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Status     string    `json:"status"`
+	StatusCode int       `json:"status_code,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	Attempt    int       `json:"attempt"`
+	Runner     string    `json:"runner,omitempty"`
+	Deployment string    `json:"deployment,omitempty"`
+	Errors     []string  `json:"errors,omitempty"`
+}
+
+// codebeat:enable[TOO_MANY_IVARS]
+
+// apiLog writes apiRecords as JSON lines. It is shared by every copy of a
+// Connection, so writes are serialized.
+type apiLog struct {
+	lock       *sync.Mutex
+	enc        *json.Encoder
+	unredacted bool
+}
+
+func newAPILog(w io.Writer, unredacted bool) *apiLog {
+	return &apiLog{
+		lock:       &sync.Mutex{},
+		enc:        json.NewEncoder(w),
+		unredacted: unredacted,
+	}
+}
+
+// call makes a single Compose API call with fn, and records it in the JSON
+// API log if it is enabled. gocomposeapi does not return responses, so when
+// logging each call gets its own client, with a debug log that is read for
+// the status lines of the responses that call received.
+func (cxn *Connection) call(method, path, deployment string, attempt int, fn func(*compose.Client) []error) []error {
+	if cxn.apiLog == nil {
+		return fn(cxn.client)
+	}
+	status := &statusRecorder{}
+	client, err := createClient(cxn.apiKey, status)
+	if err != nil {
+		return []error{err}
+	}
+	start := time.Now()
+	errs := fn(client)
+	cxn.logCall(method, path, deployment, attempt, start, status.code, errs)
+	return errs
+}
+
+// logCall writes a single Compose API call to the JSON API log. The runner
+// that triggered the call is taken from the Connection.
+func (cxn *Connection) logCall(method, path, deployment string, attempt int, start time.Time, code int, errs []error) {
+	record := apiRecord{
+		Time:       start.UTC(),
+		Method:     method,
+		Path:       path,
+		Status:     "ok",
+		StatusCode: code,
+		DurationMS: int64(time.Since(start) / time.Millisecond),
+		Attempt:    attempt,
+		Runner:     cxn.runnerAction,
+		Deployment: deployment,
+	}
+	if len(deployment) == 0 {
+		record.Deployment = cxn.runnerTarget
+	}
+	if len(errs) != 0 {
+		record.Status = "error"
+		record.Errors = cxn.apiLog.errorStrings(errs)
+	}
+
+	cxn.apiLog.lock.Lock()
+	defer cxn.apiLog.lock.Unlock()
+	// The API log is diagnostic only. Failing to write to it should not
+	// fail the operation being logged.
+	_ = cxn.apiLog.enc.Encode(record)
+}
+
+// gocomposeapi's debug log includes a dump of every response it receives,
+// starting with the status line.
+var responseStatusRegexp = regexp.MustCompile(`HTTP Response: HTTP/[0-9.]+ ([1-5][0-9]{2})\b`)
+
+// statusRecorder is the debug log of the client used for a single call. It
+// keeps the status code of the last response logged and discards the rest.
+type statusRecorder struct {
+	code int
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if match := responseStatusRegexp.FindSubmatch(p); match != nil {
+		r.code, _ = strconv.Atoi(string(match[1]))
+	}
+	return len(p), nil
+}
+
+func (l *apiLog) errorStrings(errs []error) []string {
+	strs := []string{}
+	for _, err := range errs {
+		if l.unredacted {
+			strs = append(strs, err.Error())
+		} else {
+			strs = append(strs, string(redact([]byte(err.Error()))))
+		}
+	}
+	return strs
+}
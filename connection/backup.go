@@ -0,0 +1,56 @@
+package connection
+
+import (
+	compose "github.com/benjdewan/gocomposeapi"
+	"github.com/benjdewan/pachelbel/journal"
+)
+
+// Backup makes an API call to Compose to start an on-demand backup of the
+// specified deployment, and waits on the backup recipe unless its timeout
+// is 0
+func (cxn *Connection) Backup(backup Backup) error {
+	return cxn.backup(backup.GetID(), backup.GetName(), backup.GetTimeout())
+}
+
+// BackupBeforeUpgrade does nothing unless the deployment is about to be
+// upgraded and asks to be backed up first. Otherwise it takes an on-demand
+// backup and waits for it to complete.
+func (cxn *Connection) BackupBeforeUpgrade(deployment Deployment) error {
+	if len(deployment.GetVersion()) == 0 || !deployment.GetBackupBeforeUpgrade() {
+		return nil
+	}
+	return cxn.backup(deployment.GetID(), deployment.GetName(), deployment.GetTimeout())
+}
+
+func (cxn *Connection) backup(id, name string, timeout float64) error {
+	var recipe *compose.Recipe
+	errs := cxn.call("POST", "/deployments/"+id+"/backups", name, 1, func(c *compose.Client) (errs []error) {
+		recipe, errs = c.StartBackupForDeployment(id)
+		return errs
+	})
+	if len(errs) != 0 {
+		return &APIError{Deployment: name, Op: "back up", Errs: errs}
+	}
+
+	if timeout == 0 {
+		cxn.observeRecipeID(recipe.ID)
+		return nil
+	}
+	cxn.startRecipe(journal.OpBackup, name, id, recipe.ID, timeout)
+	return cxn.wait(name, recipe.ID, timeout)
+}
+
+// Backups returns every backup of the deployment with the provided ID
+func (cxn *Connection) Backups(id, name string) ([]compose.Backup, error) {
+	var backups *[]compose.Backup
+	errs := cxn.call("GET", "/deployments/"+id+"/backups", name, 1, func(c *compose.Client) (errs []error) {
+		backups, errs = c.GetBackupsForDeployment(id)
+		return errs
+	})
+	if len(errs) != 0 {
+		return nil, &APIError{Deployment: name, Op: "list the backups of", Errs: errs}
+	} else if backups == nil {
+		return []compose.Backup{}, nil
+	}
+	return *backups, nil
+}
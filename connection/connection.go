@@ -2,11 +2,14 @@ package connection
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"sync"
+	"time"
 
 	compose "github.com/benjdewan/gocomposeapi"
 	"github.com/benjdewan/pachelbel/errorqueue"
+	"github.com/benjdewan/pachelbel/journal"
 	"github.com/benjdewan/pachelbel/output"
 	"github.com/masterminds/semver"
 )
@@ -23,6 +26,7 @@ type ExistingDeployment struct {
 	Type            string
 	Version         string
 	Upgrades        []*semver.Version
+	Transitions     []compose.VersionTransition
 }
 
 // Deployment is the interface for mutatable Compose deployments. For any
@@ -46,6 +50,19 @@ type Deployment interface {
 	GetVersion() string
 	GetWiredTiger() bool
 	GetCacheMode() bool
+	// GetWhitelist returns nil if pachelbel should not manage the
+	// deployment's whitelist
+	GetWhitelist() []WhitelistEntry
+	GetBackupBeforeUpgrade() bool
+	// GetRestoreSource returns nil if the deployment should be created
+	// empty rather than restored from a backup
+	GetRestoreSource() *RestoreSource
+}
+
+// RestoreSource identifies the backup a new deployment is restored from
+type RestoreSource struct {
+	DeploymentID string
+	BackupID     string
 }
 
 // Deprovision is the interface for deployment deprovision objects. To not wait
@@ -57,45 +74,177 @@ type Deprovision interface {
 	GetTimeout() float64
 }
 
+// GraceDeprovision is the interface for deployments to deprovision once a
+// grace period expires, rather than immediately
+type GraceDeprovision interface {
+	Deprovision
+	GetGracePeriod() time.Duration
+}
+
+// Backup is the interface for on-demand backup objects. To not wait for the
+// backup recipe to complete for the given ID, ensure GetTimeout() returns 0
+type Backup interface {
+	GetID() string
+	GetName() string
+	GetTimeout() float64
+}
+
+// RecipeWait is the interface for recipes to wait on that were not
+// necessarily started by pachelbel
+type RecipeWait interface {
+	GetRecipeID() string
+	GetTimeout() float64
+}
+
 // Connection is the struct that manages the state of provisioning
 // work done in Compose during an invocation of pachelbel.
 // codebeat:disable[TOO_MANY_IVARS]
 type Connection struct {
 	// Internal fields
-	client           *compose.Client
-	logFile          *os.File
-	accountID        string
-	newDeploymentIDs *sync.Map
+	client             *compose.Client
+	apiKey             string
+	logFile            *os.File
+	apiLog             *apiLog
+	accountID          string
+	newDeploymentIDs   *sync.Map
+	deprovisionedNames *sync.Map
+	journal            *journal.Journal
+	activeRecipes      string
+	warnings           *warnings
+
+	// The runner this Connection is being used by, if any
+	runnerAction string
+	runnerTarget string
+	observer     RecipeObserver
 }
 
 // codebeat:enable[TOO_MANY_IVARS]
 
+// Logging configures how requests to the Compose API are logged
+type Logging struct {
+	// File is the path to write API requests and responses to. Nothing
+	// is logged if it is empty.
+	File string
+
+	// Unredacted disables the masking of API tokens, passwords and
+	// certificates in the log file. It should only be used for local
+	// debugging.
+	Unredacted bool
+
+	// Format is either LogFormatText, to log raw requests and responses,
+	// or LogFormatJSON to log one JSON object per API call with timing
+	// information. LogFormatText is used if it is empty.
+	Format string
+}
+
 // New creates a new Connection struct, but does not initialize the Compose
 // connection. Invoke Init() to do so.
-func New(apiKey, logFile string) (*Connection, error) {
-	cxn := &Connection{newDeploymentIDs: &sync.Map{}}
-	var err error
-	if len(logFile) > 0 {
-		if cxn.logFile, err = os.Create(logFile); err != nil {
-			return cxn, err
-		}
+func New(apiKey string, logging Logging) (*Connection, error) {
+	cxn := &Connection{
+		newDeploymentIDs:   &sync.Map{},
+		deprovisionedNames: &sync.Map{},
+		warnings:           &warnings{},
+		apiKey:             apiKey,
+	}
+	w, err := cxn.openLog(logging)
+	if err != nil {
+		return cxn, err
 	}
-	cxn.client, err = createClient(apiKey, cxn.logFile)
+	cxn.client, err = createClient(apiKey, w)
 	if err != nil {
 		return cxn, err
 	}
 
-	cxn.accountID, err = fetchAccountID(cxn.client)
+	cxn.accountID, err = cxn.fetchAccountID()
 	return cxn, err
 }
 
+// openLog opens the log file, if one was specified, and returns the writer
+// gocomposeapi should log raw requests to, if any.
+func (cxn *Connection) openLog(logging Logging) (io.Writer, error) {
+	switch logging.Format {
+	case "", LogFormatText, LogFormatJSON:
+	default:
+		return nil, fmt.Errorf("'%s' is not a valid log format. Expected '%s' or '%s'",
+			logging.Format, LogFormatText, LogFormatJSON)
+	}
+	if len(logging.File) == 0 {
+		return nil, nil
+	}
+
+	var err error
+	if cxn.logFile, err = os.OpenFile(logging.File, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600); err != nil {
+		return nil, err
+	}
+	if logging.Format == LogFormatJSON {
+		cxn.apiLog = newAPILog(cxn.logFile, logging.Unredacted)
+		return nil, nil
+	} else if logging.Unredacted {
+		return cxn.logFile, nil
+	}
+	return newRedactingWriter(cxn.logFile), nil
+}
+
+// Policies for deployments that already have recipes running on them when
+// pachelbel needs to change them
+const (
+	// ActiveRecipesWait waits for running recipes to finish first
+	ActiveRecipesWait = "wait"
+	// ActiveRecipesFail fails without changing the deployment
+	ActiveRecipesFail = "fail"
+)
+
+// WithActiveRecipes returns a copy of the Connection that applies the
+// provided policy to deployments that already have recipes running on them
+// when they are resized, upgraded or deprovisioned. The copy shares all of
+// its state with the original.
+func (cxn *Connection) WithActiveRecipes(policy string) (*Connection, error) {
+	switch policy {
+	case ActiveRecipesWait, ActiveRecipesFail:
+	default:
+		return cxn, fmt.Errorf("'%s' is not a valid active recipe policy. Expected '%s' or '%s'",
+			policy, ActiveRecipesWait, ActiveRecipesFail)
+	}
+	policyCXN := *cxn
+	policyCXN.activeRecipes = policy
+	return &policyCXN, nil
+}
+
+// RecipeObserver is notified about the recipes a runner is waiting on
+type RecipeObserver interface {
+	RecipeID(name, recipeID string)
+	RecipeStatus(name, recipeID, status, step string)
+}
+
+// WithObserver returns a copy of the Connection that reports the recipes
+// runners wait on to the provided observer. The copy shares all of its
+// state with the original.
+func (cxn *Connection) WithObserver(observer RecipeObserver) *Connection {
+	observedCXN := *cxn
+	observedCXN.observer = observer
+	return &observedCXN
+}
+
+// ForRunner returns a copy of the Connection that attributes the API calls
+// it makes to the runner performing action on the named deployment. The
+// copy shares all of its state with the original.
+func (cxn *Connection) ForRunner(action, name string) *Connection {
+	runnerCXN := *cxn
+	runnerCXN.runnerAction = action
+	runnerCXN.runnerTarget = name
+	return &runnerCXN
+}
+
 // AddTeams adds teams to the deployment specified by the ID with the roles provided
 func (cxn *Connection) AddTeams(id string, deployment Deployment) error {
 	teamRoles := deployment.GetTeamRoles()
-	existingRoles, errs := cxn.client.GetTeamRoles(id)
+	var existingRoles *[]compose.TeamRole
+	errs := cxn.call("GET", "/deployments/"+id+"/teamroles", deployment.GetName(), 1, func(c *compose.Client) (errs []error) {
+		existingRoles, errs = c.GetTeamRoles(id)
+		return errs
+	})
 	if len(errs) != 0 {
-		return fmt.Errorf("Unable to retrieve team_role information for '%s':\n%v\n",
-			deployment.GetName(), errs)
+		return &APIError{Deployment: deployment.GetName(), Op: "retrieve team_role information for", Errs: errs}
 	}
 	if len(teamRoles) == 0 {
 		return nil
@@ -116,11 +265,16 @@ func (cxn *Connection) AddTeams(id string, deployment Deployment) error {
 				TeamID: teamID,
 			}
 
-			_, createErrs := cxn.client.CreateTeamRole(id, params)
+			createErrs := cxn.call("POST", "/deployments/"+id+"/teamroles", deployment.GetName(), 1, func(c *compose.Client) []error {
+				_, errs := c.CreateTeamRole(id, params)
+				return errs
+			})
 			if createErrs != nil {
-				return fmt.Errorf("Unable to add team '%s' as '%s' to %s:\n%v\n",
-					teamID, role, deployment.GetName(),
-					createErrs)
+				return &APIError{
+					Deployment: deployment.GetName(),
+					Op:         fmt.Sprintf("add team '%s' as '%s' to", teamID, role),
+					Errs:       createErrs,
+				}
 			}
 		}
 	}
@@ -132,6 +286,12 @@ func (cxn *Connection) Add(id string) {
 	cxn.newDeploymentIDs.Store(id, struct{}{})
 }
 
+// AddDeprovisioned adds a deployment name to a connection object's internal
+// tracker of deprovisioned deployments, which are dropped from merged output
+func (cxn *Connection) AddDeprovisioned(name string) {
+	cxn.deprovisionedNames.Store(name, struct{}{})
+}
+
 // GetAndAdd retrieves the latest deployment information about the named
 // deployment and stores its ID
 func (cxn *Connection) GetAndAdd(name string) error {
@@ -139,15 +299,17 @@ func (cxn *Connection) GetAndAdd(name string) error {
 }
 
 func (cxn *Connection) getAndAddRetryable(name string, retries, max int) error {
-	deployment, errs := cxn.client.GetDeploymentByName(name)
-	retries += 1
+	retries++
+	var deployment *compose.Deployment
+	errs := cxn.call("GET", "/deployments/name/"+name, name, retries, func(c *compose.Client) (errs []error) {
+		deployment, errs = c.GetDeploymentByName(name)
+		return errs
+	})
 	if len(errs) != 0 {
-		if retries >= max {
-			return fmt.Errorf("Unable to get the latest details of '%s':\n%v", name, errs)
-		} else if len(errs) == 1 && isEOF(errs[0]) {
+		if retries < max && len(errs) == 1 && isEOF(errs[0]) {
 			return cxn.getAndAddRetryable(name, retries, max)
 		}
-		return fmt.Errorf("Unable to get the latest details of '%s':\n%v", name, errs)
+		return &APIError{Deployment: name, Op: "get the latest details of", Errs: errs}
 	}
 	cxn.newDeploymentIDs.Store(deployment.ID, struct{}{})
 	return nil
@@ -157,9 +319,13 @@ func (cxn *Connection) getAndAddRetryable(name string, retries, max int) error {
 func (cxn *Connection) Clusters() (map[string]string, error) {
 	clusters := make(map[string]string)
 
-	clusterList, errs := cxn.client.GetClusters()
+	var clusterList *[]compose.Cluster
+	errs := cxn.call("GET", "/clusters", "", 1, func(c *compose.Client) (errs []error) {
+		clusterList, errs = c.GetClusters()
+		return errs
+	})
 	if len(errs) != 0 || clusters == nil {
-		return clusters, fmt.Errorf("Failed to get cluster information:\n%s", errs)
+		return clusters, &APIError{Op: "get cluster information", Errs: errs}
 	}
 
 	for _, cluster := range *clusterList {
@@ -174,9 +340,13 @@ func (cxn *Connection) Clusters() (map[string]string, error) {
 func (cxn *Connection) Datacenters() (map[string]struct{}, error) {
 	datacenters := make(map[string]struct{})
 
-	datacenterObjs, errs := cxn.client.GetDatacenters()
+	var datacenterObjs *[]compose.Datacenter
+	errs := cxn.call("GET", "/datacenters", "", 1, func(c *compose.Client) (errs []error) {
+		datacenterObjs, errs = c.GetDatacenters()
+		return errs
+	})
 	if len(errs) != 0 || datacenterObjs == nil {
-		return datacenters, fmt.Errorf("Failed to get datacenter information:\n%v", errs)
+		return datacenters, &APIError{Op: "get datacenter information", Errs: errs}
 	}
 
 	for _, datacenter := range *datacenterObjs {
@@ -190,9 +360,13 @@ func (cxn *Connection) Datacenters() (map[string]struct{}, error) {
 // to the versions (both supported and deprecated) Compose works with.
 // New deployments cannot be made using deprecated versions
 func (cxn *Connection) SupportedDatabases() (map[string][]string, error) {
-	dbs, errs := cxn.client.GetDatabases()
+	var dbs *[]compose.Database
+	errs := cxn.call("GET", "/databases", "", 1, func(c *compose.Client) (errs []error) {
+		dbs, errs = c.GetDatabases()
+		return errs
+	})
 	if len(errs) != 0 {
-		return nil, fmt.Errorf("Unable to enumerate supported database types:\n%v", errs)
+		return nil, &APIError{Op: "enumerate supported database types", Errs: errs}
 	}
 	return buildDatabaseVersionMap(*dbs), nil
 }
@@ -200,20 +374,16 @@ func (cxn *Connection) SupportedDatabases() (map[string][]string, error) {
 // ExistingDeployment returns an ExistingDeployment struct if the provided
 // input is the ID or Name of a deployment (ID is weighted above Name).
 func (cxn *Connection) ExistingDeployment(idOrName string) (ExistingDeployment, error) {
-	deployment, errs := cxn.client.GetDeployment(idOrName)
-	if len(errs) == 0 && deployment != nil {
-		return cxn.existingDeployment(*deployment)
-	}
-	deployment, errs = cxn.client.GetDeploymentByName(idOrName)
-	if len(errs) == 0 && deployment != nil {
-		return cxn.existingDeployment(*deployment)
+	deployment, err := cxn.resolveDeployment(idOrName)
+	if err != nil {
+		return ExistingDeployment{}, err
 	}
-	return ExistingDeployment{}, fmt.Errorf("Unable to resolve '%s' as a deployment id or name:\n%v", idOrName, errs)
+	return cxn.existingDeployment(*deployment)
 }
 
 // ConnectionYAML writes out the connection strings for all the
-// provisioned deployments as a YAML object to the provided file.
-func (cxn *Connection) ConnectionYAML(endpointMap map[string]string, outFile string) error {
+// provisioned deployments as a YAML object to the provided destination.
+func (cxn *Connection) ConnectionYAML(endpointMap map[string]string, dst output.Destination) error {
 	q := errorqueue.New()
 	builder := output.New(endpointMap)
 	cxn.newDeploymentIDs.Range(func(key, value interface{}) bool {
@@ -223,13 +393,48 @@ func (cxn *Connection) ConnectionYAML(endpointMap map[string]string, outFile str
 		}
 		return true
 	})
+	cxn.deprovisionedNames.Range(func(key, value interface{}) bool {
+		builder.Remove(key.(string))
+		return true
+	})
+
+	if dst.Merge {
+		if err := builder.Merge(dst); err != nil {
+			q.Enqueue(err)
+			return q.Flush()
+		}
+	}
 
-	if err := builder.Write(outFile); err != nil {
+	if err := builder.Write(dst); err != nil {
 		q.Enqueue(err)
 	}
 	return q.Flush()
 }
 
+// TeamRoles returns the IDs of the teams with each role on the deployment
+// with the provided ID, keyed by role
+func (cxn *Connection) TeamRoles(id, name string) (map[string][]string, error) {
+	var roles *[]compose.TeamRole
+	errs := cxn.call("GET", "/deployments/"+id+"/teamroles", name, 1, func(c *compose.Client) (errs []error) {
+		roles, errs = c.GetTeamRoles(id)
+		return errs
+	})
+	if len(errs) != 0 {
+		return nil, &APIError{Deployment: name, Op: "retrieve team_role information for", Errs: errs}
+	}
+
+	teamRoles := make(map[string][]string)
+	if roles == nil {
+		return teamRoles, nil
+	}
+	for _, role := range *roles {
+		for _, team := range role.Teams {
+			teamRoles[role.Name] = append(teamRoles[role.Name], team.ID)
+		}
+	}
+	return teamRoles, nil
+}
+
 // Close closes any open connections and/or files possessed by the Connection
 // instance.
 func (cxn *Connection) Close() error {
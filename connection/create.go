@@ -1,20 +1,57 @@
 package connection
 
 import (
-	"fmt"
-
 	compose "github.com/benjdewan/gocomposeapi"
+	"github.com/benjdewan/pachelbel/journal"
 )
 
-// CreateDeployment creates a deployment in Compose and returns it on success
+// CreateDeployment creates a deployment in Compose, or restores one from a
+// backup if the deployment has a restore source, and returns it on success.
+// Restoring cannot set the scaling or notes of the new deployment, so they
+// are applied once the restore finishes.
 func (cxn *Connection) CreateDeployment(d Deployment) (*compose.Deployment, error) {
-	newDeployment, errs := cxn.client.CreateDeployment(deploymentParams(d, cxn.accountID))
+	if err := cxn.assertIdle(d.GetName()); err != nil {
+		return nil, err
+	}
+
+	var (
+		newDeployment *compose.Deployment
+		errs          []error
+	)
+	if src := d.GetRestoreSource(); src != nil {
+		errs = cxn.call("POST", "/deployments/"+src.DeploymentID+"/backups/"+src.BackupID+"/restore", d.GetName(), 1, func(c *compose.Client) (errs []error) {
+			newDeployment, errs = c.RestoreBackup(restoreParams(d, src))
+			return errs
+		})
+	} else {
+		errs = cxn.call("POST", "/deployments", d.GetName(), 1, func(c *compose.Client) (errs []error) {
+			newDeployment, errs = c.CreateDeployment(deploymentParams(d, cxn.accountID))
+			return errs
+		})
+	}
 	if len(errs) != 0 {
-		return nil, fmt.Errorf("Unable to create '%s': %v\n",
-			d.GetName(), errs)
+		return nil, &APIError{Deployment: d.GetName(), Op: "create", Errs: errs}
 	}
 
-	return newDeployment, cxn.wait(newDeployment.ProvisionRecipeID, d.GetTimeout())
+	cxn.startRecipe(journal.OpCreate, d.GetName(), newDeployment.ID, newDeployment.ProvisionRecipeID, d.GetTimeout())
+	if err := cxn.wait(d.GetName(), newDeployment.ProvisionRecipeID, d.GetTimeout()); err != nil {
+		return newDeployment, err
+	} else if d.GetRestoreSource() == nil {
+		return newDeployment, nil
+	}
+	return newDeployment, cxn.applyRestoredSettings(newDeployment.ID, d)
+}
+
+func (cxn *Connection) applyRestoredSettings(id string, d Deployment) error {
+	if d.GetScaling() > 0 {
+		if err := cxn.resize(id, d.GetName(), d.GetScaling(), d.GetTimeout()); err != nil {
+			return err
+		}
+	}
+	if len(d.GetNotes()) == 0 {
+		return nil
+	}
+	return cxn.setNotes(id, d.GetName(), d.GetNotes())
 }
 
 func deploymentParams(deployment Deployment, accountID string) compose.DeploymentParams {
@@ -27,12 +64,33 @@ func deploymentParams(deployment Deployment, accountID string) compose.Deploymen
 		Version:      deployment.GetVersion(),
 		CacheMode:    deployment.GetCacheMode(),
 		WiredTiger:   deployment.GetWiredTiger(),
-		Units:        deployment.GetScaling(),
+		Units:        1,
+	}
+	if deployment.GetScaling() > 1 {
+		dParams.Units = deployment.GetScaling()
 	}
 
 	return setDeploymentType(deployment, dParams)
 }
 
+// restoreParams only supports cluster and datacenter deployments.
+// Provisioning tags must be rejected during validation.
+func restoreParams(deployment Deployment, src *RestoreSource) compose.RestoreBackupParams {
+	params := compose.RestoreBackupParams{
+		DeploymentID: src.DeploymentID,
+		BackupID:     src.BackupID,
+		Name:         deployment.GetName(),
+		Version:      deployment.GetVersion(),
+		SSL:          deployment.GetSSL(),
+	}
+	if deployment.ClusterDeployment() {
+		params.ClusterID = deployment.GetCluster()
+	} else {
+		params.Datacenter = deployment.GetDatacenter()
+	}
+	return params
+}
+
 func setDeploymentType(deployment Deployment, dParams compose.DeploymentParams) compose.DeploymentParams {
 	if deployment.TagDeployment() {
 		dParams.ProvisioningTags = deployment.GetTags()
@@ -1,19 +1,44 @@
 package connection
 
-import "fmt"
+import (
+	compose "github.com/benjdewan/gocomposeapi"
+	"github.com/benjdewan/pachelbel/journal"
+)
 
 // Deprovision makes an API call to compose to deprovision the specified
-// deployment
+// deployment. Deprovision recipes that are not waited on stay in the
+// journal until a later run of pachelbel sees them finish.
 func (cxn *Connection) Deprovision(deprovision Deprovision) error {
-	recipe, errs := cxn.client.DeprovisionDeployment(deprovision.GetID())
-	if len(errs) != 0 {
-		return fmt.Errorf("Unable to deprovision '%s':\n%v",
-			deprovision.GetName(), errs)
+	if err := cxn.assertIdle(deprovision.GetName()); err != nil {
+		return err
+	} else if err := cxn.awaitActiveRecipes(deprovision.GetName(), deprovision.GetID(), activeRecipeTimeout(deprovision)); err != nil {
+		return err
 	}
 
+	var recipe *compose.Recipe
+	errs := cxn.call("DELETE", "/deployments/"+deprovision.GetID(), deprovision.GetName(), 1, func(c *compose.Client) (errs []error) {
+		recipe, errs = c.DeprovisionDeployment(deprovision.GetID())
+		return errs
+	})
+	if len(errs) != 0 {
+		return &APIError{Deployment: deprovision.GetName(), Op: "deprovision", Errs: errs}
+	}
+	cxn.AddDeprovisioned(deprovision.GetName())
+	cxn.startRecipe(journal.OpDeprovision, deprovision.GetName(), deprovision.GetID(), recipe.ID, activeRecipeTimeout(deprovision))
 	if deprovision.GetTimeout() == 0 {
+		cxn.observeRecipeID(recipe.ID)
 		return nil
 	}
+	return cxn.wait(deprovision.GetName(), recipe.ID, deprovision.GetTimeout())
+}
 
-	return cxn.wait(recipe.ID, deprovision.GetTimeout())
+// activeRecipeTimeout is how long to wait on recipes already running on a
+// deployment before deprovisioning it. Deprovisions that are not waited on
+// still wait on them for the default timeout, and later runs resume them
+// with it.
+func activeRecipeTimeout(deprovision Deprovision) float64 {
+	if deprovision.GetTimeout() == 0 {
+		return 900
+	}
+	return deprovision.GetTimeout()
 }
@@ -0,0 +1,44 @@
+package connection
+
+import (
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+// Description is everything pachelbel can look up about an existing
+// deployment
+type Description struct {
+	ExistingDeployment
+	Deployment *compose.Deployment
+	TeamRoles  map[string][]string
+	Whitelist  []WhitelistEntry
+	// Recipes are the most recent recipes run on the deployment, newest first
+	Recipes []compose.Recipe
+}
+
+// Describe looks up the deployment with the provided ID or name along with
+// its team roles, whitelist and up to maxRecipes of its most recent recipes
+func (cxn *Connection) Describe(idOrName string, maxRecipes int) (Description, error) {
+	deployment, err := cxn.resolveDeployment(idOrName)
+	if err != nil {
+		return Description{}, err
+	}
+	description := Description{Deployment: deployment}
+
+	if description.ExistingDeployment, err = cxn.existingDeployment(*deployment); err != nil {
+		return description, err
+	}
+	if description.TeamRoles, err = cxn.TeamRoles(deployment.ID, deployment.Name); err != nil {
+		return description, err
+	}
+	if description.Whitelist, err = cxn.Whitelist(deployment.ID, deployment.Name); err != nil {
+		return description, err
+	}
+
+	if description.Recipes, err = cxn.Recipes(deployment.ID, deployment.Name); err != nil {
+		return description, err
+	}
+	if maxRecipes >= 0 && len(description.Recipes) > maxRecipes {
+		description.Recipes = description.Recipes[:maxRecipes]
+	}
+	return description, nil
+}
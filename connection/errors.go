@@ -0,0 +1,122 @@
+package connection
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// APIError is returned when a request to the Compose API fails
+type APIError struct {
+	// Deployment is the name or ID of the deployment the request was
+	// for, if any
+	Deployment string
+	// Op describes the failed operation, e.g. "resize"
+	Op string
+	// Errs are the errors returned by the Compose API client
+	Errs []error
+}
+
+func (e *APIError) Error() string {
+	if len(e.Deployment) == 0 {
+		return fmt.Sprintf("Unable to %s:\n%v", e.Op, e.Errs)
+	}
+	return fmt.Sprintf("Unable to %s '%s':\n%v", e.Op, e.Deployment, e.Errs)
+}
+
+// RecipeTimeoutError is returned when a recipe does not finish within the
+// timeout a deployment specifies
+type RecipeTimeoutError struct {
+	Deployment string
+	RecipeID   string
+	Timeout    float64
+}
+
+func (e *RecipeTimeoutError) Error() string {
+	return fmt.Sprintf("Timed out after %gs waiting on recipe %s for '%s' to complete",
+		e.Timeout, e.RecipeID, e.Deployment)
+}
+
+// RecipeFailedError is returned when a recipe finishes unsuccessfully
+type RecipeFailedError struct {
+	Deployment string
+	RecipeID   string
+	Status     string
+	// Details describes the recipe and any of its steps that failed
+	Details []string
+}
+
+func (e *RecipeFailedError) Error() string {
+	msg := fmt.Sprintf("Recipe %s for '%s' finished with status '%s'",
+		e.RecipeID, e.Deployment, e.Status)
+	if len(e.Details) == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s:\n%s", msg, strings.Join(e.Details, "\n"))
+}
+
+// ActiveRecipesError is returned instead of changing a deployment that
+// other recipes are running on, when pachelbel is not waiting on them
+type ActiveRecipesError struct {
+	Deployment string
+	// Recipes describes each running recipe
+	Recipes []string
+}
+
+func (e *ActiveRecipesError) Error() string {
+	return fmt.Sprintf("Refusing to change '%s' while other recipes are running on it:\n%s",
+		e.Deployment, strings.Join(e.Recipes, "\n"))
+}
+
+// RecipeInFlightError is returned instead of changing a deployment that
+// a recipe started by an earlier run of pachelbel is still running on
+type RecipeInFlightError struct {
+	Deployment string
+	RecipeID   string
+	Operation  string
+}
+
+func (e *RecipeInFlightError) Error() string {
+	return fmt.Sprintf("Refusing to change '%s' while the %s recipe %s started by an earlier run is unfinished. Run 'pachelbel wait' to wait on it",
+		e.Deployment, e.Operation, e.RecipeID)
+}
+
+// NotFound reports whether the Compose API responded with 404 Not Found
+func (e *APIError) NotFound() bool {
+	return notFound(e.Errs)
+}
+
+// NotFoundError is returned when no deployment has the provided ID or name
+type NotFoundError struct {
+	Deployment string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("No deployment has the ID or name '%s'", e.Deployment)
+}
+
+// notFound reports whether errs from the Compose API client describe a
+// missing resource. The client does not expose response codes, so this
+// relies on the text of its errors.
+func notFound(errs []error) bool {
+	msgs := []string{}
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return statusCode(msgs) == 404
+}
+
+// gocomposeapi includes the status code in the errors it returns for
+// unsuccessful requests.
+var statusCodeRegexp = regexp.MustCompile(`(?i)(?:^|status(?: code)?:?\s*)([1-5][0-9]{2})\b`)
+
+func statusCode(errs []string) int {
+	for _, err := range errs {
+		if match := statusCodeRegexp.FindStringSubmatch(err); match != nil {
+			code, _ := strconv.Atoi(match[1])
+			return code
+		}
+	}
+	return 0
+}
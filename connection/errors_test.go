@@ -0,0 +1,19 @@
+package connection
+
+import "testing"
+
+func TestStatusCode(t *testing.T) {
+	for i, test := range []struct {
+		errs     []string
+		expected int
+	}{
+		{errs: []string{"503 Service Unavailable"}, expected: 503},
+		{errs: []string{"unexpected status code: 404"}, expected: 404},
+		{errs: []string{"dial tcp 10.0.0.1:443: i/o timeout"}, expected: 0},
+		{errs: []string{}, expected: 0},
+	} {
+		if actual := statusCode(test.errs); actual != test.expected {
+			t.Errorf("Test #%d: Expected %d but saw %d", i, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package connection
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+// deletionMarker is appended to the notes of deployments scheduled to be
+// deprovisioned once their grace period expires
+const deletionMarker = "[pachelbel] pending deletion, expires %s"
+
+var deletionMarkerPattern = regexp.MustCompile(`(?m)^\[pachelbel\] pending deletion, expires (\S+)\n?`)
+
+// ScheduleDeprovision takes a final backup of a deployment and marks it in
+// its notes to be deprovisioned by Reap() once its grace period expires.
+// The backup is always waited on, using the deployment's timeout or the
+// default if that is 0.
+func (cxn *Connection) ScheduleDeprovision(deprovision GraceDeprovision) error {
+	name, id := deprovision.GetName(), deprovision.GetID()
+	if err := cxn.assertIdle(name); err != nil {
+		return err
+	} else if err := cxn.awaitActiveRecipes(name, id, activeRecipeTimeout(deprovision)); err != nil {
+		return err
+	}
+
+	deployment, err := cxn.resolveDeployment(id)
+	if err != nil {
+		return err
+	}
+	if err = cxn.backup(id, name, activeRecipeTimeout(deprovision)); err != nil {
+		return err
+	}
+	expiry := time.Now().Add(deprovision.GetGracePeriod())
+	return cxn.setNotes(id, name, MarkForDeletion(deployment.Notes, expiry))
+}
+
+// Reap deprovisions a deployment if it is still marked for deletion and its
+// grace period has expired. The notes are read again first so deployments
+// undeleted since they were selected are left alone.
+func (cxn *Connection) Reap(deprovision Deprovision) error {
+	deployment, err := cxn.resolveDeployment(deprovision.GetID())
+	if err != nil {
+		return err
+	}
+	if expiry, ok := DeletionExpiry(deployment.Notes); !ok || expiry.After(time.Now()) {
+		return fmt.Errorf("'%s' is no longer pending deletion or its grace period has not expired. Not deprovisioning it",
+			deprovision.GetName())
+	}
+	return cxn.Deprovision(deprovision)
+}
+
+// Undelete removes the deletion marker from the notes of a deployment
+// scheduled to be deprovisioned. It returns false if the deployment was not
+// scheduled to be deprovisioned.
+func (cxn *Connection) Undelete(idOrName string) (bool, error) {
+	deployment, err := cxn.resolveDeployment(idOrName)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := DeletionExpiry(deployment.Notes); !ok {
+		return false, nil
+	}
+	notes := ClearDeletionMarker(deployment.Notes)
+	if len(notes) == 0 {
+		// The Compose API ignores empty notes, so they cannot be cleared
+		notes = fmt.Sprintf("[pachelbel] deletion cancelled at %s", time.Now().UTC().Format(time.RFC3339))
+	}
+	return true, cxn.setNotes(deployment.ID, deployment.Name, notes)
+}
+
+// MarkForDeletion returns notes with a deletion marker expiring at the
+// provided time, replacing any existing marker
+func MarkForDeletion(notes string, expiry time.Time) string {
+	marker := fmt.Sprintf(deletionMarker, expiry.UTC().Format(time.RFC3339))
+	if notes = ClearDeletionMarker(notes); len(notes) == 0 {
+		return marker
+	}
+	return notes + "\n" + marker
+}
+
+// DeletionExpiry returns the time the grace period of a deployment marked
+// for deletion expires, and false if the notes have no deletion marker
+func DeletionExpiry(notes string) (time.Time, bool) {
+	match := deletionMarkerPattern.FindStringSubmatch(notes)
+	if match == nil {
+		return time.Time{}, false
+	}
+	expiry, err := time.Parse(time.RFC3339, match[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiry, true
+}
+
+// ClearDeletionMarker returns notes without any deletion marker
+func ClearDeletionMarker(notes string) string {
+	return strings.TrimSpace(deletionMarkerPattern.ReplaceAllString(notes, ""))
+}
+
+// PendingDeletion splits the deployments marked for deletion into those whose
+// grace period has expired and those whose grace period has not
+func PendingDeletion(deployments []compose.Deployment, now time.Time) ([]compose.Deployment, []compose.Deployment) {
+	expired, pending := []compose.Deployment{}, []compose.Deployment{}
+	for _, deployment := range deployments {
+		expiry, ok := DeletionExpiry(deployment.Notes)
+		if !ok {
+			continue
+		} else if expiry.After(now) {
+			pending = append(pending, deployment)
+		} else {
+			expired = append(expired, deployment)
+		}
+	}
+	return expired, pending
+}
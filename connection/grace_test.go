@@ -0,0 +1,62 @@
+package connection
+
+import (
+	"testing"
+	"time"
+
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+func TestDeletionMarker(t *testing.T) {
+	expiry := time.Date(2017, time.October, 3, 12, 0, 0, 0, time.UTC)
+	for i, test := range deletionMarkerTests {
+		marked := MarkForDeletion(test.notes, expiry)
+		if marked != test.marked {
+			t.Errorf("Test #%d: Expected '%s' but saw '%s'", i, test.marked, marked)
+		}
+		if actual, ok := DeletionExpiry(marked); !ok || !actual.Equal(expiry) {
+			t.Errorf("Test #%d: Expected the marker to expire at %v but saw %v", i, expiry, actual)
+		}
+		if cleared := ClearDeletionMarker(marked); cleared != test.cleared {
+			t.Errorf("Test #%d: Expected '%s' but saw '%s'", i, test.cleared, cleared)
+		}
+	}
+}
+
+var deletionMarkerTests = []struct {
+	notes   string
+	marked  string
+	cleared string
+}{
+	{
+		notes:   "",
+		marked:  "[pachelbel] pending deletion, expires 2017-10-03T12:00:00Z",
+		cleared: "",
+	},
+	{
+		notes:   "Owned by search",
+		marked:  "Owned by search\n[pachelbel] pending deletion, expires 2017-10-03T12:00:00Z",
+		cleared: "Owned by search",
+	},
+	{
+		notes:   "Owned by search\n[pachelbel] pending deletion, expires 2017-09-01T00:00:00Z",
+		marked:  "Owned by search\n[pachelbel] pending deletion, expires 2017-10-03T12:00:00Z",
+		cleared: "Owned by search",
+	},
+}
+
+func TestPendingDeletion(t *testing.T) {
+	now := time.Date(2017, time.October, 1, 0, 0, 0, 0, time.UTC)
+	deployments := []compose.Deployment{
+		{Name: "expired", Notes: MarkForDeletion("", now.Add(-time.Hour))},
+		{Name: "pending", Notes: MarkForDeletion("notes", now.Add(time.Hour))},
+		{Name: "unmarked", Notes: "pending deletion, expires 2017-09-01T00:00:00Z"},
+	}
+	expired, pending := PendingDeletion(deployments, now)
+	if len(expired) != 1 || expired[0].Name != "expired" {
+		t.Errorf("Expected only 'expired' to have expired, but saw %+v", expired)
+	}
+	if len(pending) != 1 || pending[0].Name != "pending" {
+		t.Errorf("Expected only 'pending' to be pending, but saw %+v", pending)
+	}
+}
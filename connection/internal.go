@@ -16,13 +16,55 @@ func (cxn *Connection) addToBuilder(id string, b *output.Builder) error {
 	if output.IsFake(id) {
 		return b.AddFake(id)
 	}
-	deployment, errs := cxn.client.GetDeployment(id)
+	var deployment *compose.Deployment
+	errs := cxn.call("GET", "/deployments/"+id, "", 1, func(c *compose.Client) (errs []error) {
+		deployment, errs = c.GetDeployment(id)
+		return errs
+	})
 	if len(errs) != 0 {
-		return fmt.Errorf("Unable to get deployment information for '%s':\n%v", id, errs)
+		return &APIError{Deployment: id, Op: "get deployment information for", Errs: errs}
 	}
 	return b.Add(deployment)
 }
 
+// resolveDeployment looks up a deployment by ID, and then by name. It
+// returns a *NotFoundError if neither lookup finds one.
+func (cxn *Connection) resolveDeployment(idOrName string) (*compose.Deployment, error) {
+	var deployment *compose.Deployment
+	errs := cxn.call("GET", "/deployments/"+idOrName, idOrName, 1, func(c *compose.Client) (errs []error) {
+		deployment, errs = c.GetDeployment(idOrName)
+		return errs
+	})
+	if len(errs) == 0 && deployment != nil {
+		return deployment, nil
+	}
+	errs = cxn.call("GET", "/deployments/name/"+idOrName, idOrName, 1, func(c *compose.Client) (errs []error) {
+		deployment, errs = c.GetDeploymentByName(idOrName)
+		return errs
+	})
+	if len(errs) == 0 && deployment != nil {
+		return deployment, nil
+	} else if len(errs) == 0 || notFoundByName(errs) {
+		return nil, &NotFoundError{Deployment: idOrName}
+	}
+	return nil, &APIError{Deployment: idOrName, Op: "resolve a deployment id or name from", Errs: errs}
+}
+
+// notFoundByName reports whether errs from looking up a deployment by name
+// mean that no deployment has that name. The client searches the account's
+// deployments itself, and reports a missing name without a status code.
+func notFoundByName(errs []error) bool {
+	if notFound(errs) {
+		return true
+	}
+	for _, err := range errs {
+		if strings.Contains(strings.ToLower(err.Error()), "not found") {
+			return true
+		}
+	}
+	return false
+}
+
 func (cxn *Connection) existingDeployment(deployment compose.Deployment) (ExistingDeployment, error) {
 	existing := ExistingDeployment{
 		ID:      deployment.ID,
@@ -32,17 +74,26 @@ func (cxn *Connection) existingDeployment(deployment compose.Deployment) (Existi
 		Version: deployment.Version,
 	}
 
-	transitions, errs := cxn.client.GetVersionsForDeployment(deployment.ID)
+	var transitions *[]compose.VersionTransition
+	errs := cxn.call("GET", "/deployments/"+deployment.ID+"/versions", deployment.Name, 1, func(c *compose.Client) (errs []error) {
+		transitions, errs = c.GetVersionsForDeployment(deployment.ID)
+		return errs
+	})
 	if len(errs) != 0 {
-		return existing, fmt.Errorf("Unable to get upgrade details for '%s'", deployment.Name)
+		return existing, &APIError{Deployment: deployment.Name, Op: "get upgrade details for", Errs: errs}
 	}
 	if transitions != nil {
 		existing.Upgrades = upgradeList(*transitions)
+		existing.Transitions = *transitions
 	}
 
-	scalings, errs := cxn.client.GetScalings(deployment.ID)
+	var scalings *compose.Scalings
+	errs = cxn.call("GET", "/deployments/"+deployment.ID+"/scalings", deployment.Name, 1, func(c *compose.Client) (errs []error) {
+		scalings, errs = c.GetScalings(deployment.ID)
+		return errs
+	})
 	if len(errs) != 0 {
-		return existing, fmt.Errorf("Unable to get scaling details for '%s'", deployment.Name)
+		return existing, &APIError{Deployment: deployment.Name, Op: "get scaling details for", Errs: errs}
 	}
 
 	existing.Scaling = scalings.AllocatedUnits
@@ -79,18 +130,203 @@ func buildDatabaseVersionSlice(vs []compose.Version) []string {
 	return versions
 }
 
-func (cxn *Connection) wait(recipeID string, timeout float64) error {
-	start := time.Now()
-	for time.Since(start).Seconds() <= timeout {
-		if recipe, errs := cxn.client.GetRecipe(recipeID); len(errs) != 0 {
-			return fmt.Errorf("Error waiting on recipe %v:\n%v\n",
-				recipeID, errs)
-		} else if recipe.Status == "complete" {
-			return nil
+const (
+	minPollInterval = 2 * time.Second
+	maxPollInterval = 30 * time.Second
+)
+
+func (cxn *Connection) wait(name, recipeID string, timeout float64) error {
+	cxn.observeRecipeID(recipeID)
+	var err error
+	done := poll(time.Duration(timeout*float64(time.Second)), realClock, func(attempt int) bool {
+		var recipe *compose.Recipe
+		errs := cxn.call("GET", "/recipes/"+recipeID, name, attempt, func(c *compose.Client) (errs []error) {
+			recipe, errs = c.GetRecipe(recipeID)
+			return errs
+		})
+		if len(errs) != 0 {
+			apiErr := &APIError{Deployment: name, Op: fmt.Sprintf("get the status of recipe %s for", recipeID), Errs: errs}
+			if apiErr.NotFound() {
+				// A recipe Compose no longer has can never finish, so
+				// it must not block later runs.
+				cxn.finishRecipe(name, recipeID)
+			}
+			err = apiErr
+			return true
 		}
-		time.Sleep(5 * time.Second)
+		cxn.observeRecipe(recipe)
+		if recipe.Status == "complete" {
+			cxn.finishRecipe(name, recipeID)
+			return true
+		} else if recipeFailed(recipe.Status) {
+			cxn.finishRecipe(name, recipeID)
+			err = &RecipeFailedError{
+				Deployment: name,
+				RecipeID:   recipeID,
+				Status:     recipe.Status,
+				Details:    failureDetails(recipe),
+			}
+			return true
+		}
+		return false
+	})
+	if !done {
+		return &RecipeTimeoutError{Deployment: name, RecipeID: recipeID, Timeout: timeout}
 	}
-	return fmt.Errorf("Timed out waiting on recipe %v to complete", recipeID)
+	return err
+}
+
+// pollClock lets tests control the passage of time while polling
+type pollClock struct {
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+var realClock = pollClock{now: time.Now, sleep: time.Sleep}
+
+// poll calls check, backing off between calls, until it returns true or
+// the timeout elapses. The last sleep ends at the deadline and is followed
+// by one final call, so something that finishes during the last interval
+// is still seen. It returns false if the timeout elapsed first.
+func poll(timeout time.Duration, clock pollClock, check func(attempt int) bool) bool {
+	deadline := clock.now().Add(timeout)
+	interval := minPollInterval
+	for attempt := 1; ; attempt++ {
+		if check(attempt) {
+			return true
+		}
+		remaining := deadline.Sub(clock.now())
+		if remaining <= 0 {
+			return false
+		}
+		clock.sleep(nextSleep(interval, remaining))
+		interval = backoff(interval)
+	}
+}
+
+// awaitActiveRecipes checks for recipes already running on a deployment
+// before it is changed. Depending on the policy it either waits on each of
+// them, using the deployment's timeout, or returns an *ActiveRecipesError.
+func (cxn *Connection) awaitActiveRecipes(name, id string, timeout float64) error {
+	var recipes *[]compose.Recipe
+	errs := cxn.call("GET", "/deployments/"+id+"/recipes", name, 1, func(c *compose.Client) (errs []error) {
+		recipes, errs = c.GetRecipesForDeployment(id)
+		return errs
+	})
+	if len(errs) != 0 {
+		return &APIError{Deployment: name, Op: "list the recipes running on", Errs: errs}
+	} else if recipes == nil {
+		return nil
+	}
+
+	active := activeRecipes(*recipes)
+	if len(active) == 0 {
+		return nil
+	} else if cxn.activeRecipes == ActiveRecipesFail {
+		descriptions := []string{}
+		for _, recipe := range active {
+			descriptions = append(descriptions, fmt.Sprintf("- %s (%s) is %s", recipe.ID, recipe.Name, recipe.Status))
+		}
+		return &ActiveRecipesError{Deployment: name, Recipes: descriptions}
+	}
+
+	for _, recipe := range active {
+		if err := cxn.wait(name, recipe.ID, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activeRecipes returns the recipes that have not yet finished
+func activeRecipes(recipes []compose.Recipe) []compose.Recipe {
+	active := []compose.Recipe{}
+	for _, recipe := range recipes {
+		if recipe.Status != "complete" && !recipeFailed(recipe.Status) {
+			active = append(active, recipe)
+		}
+	}
+	return active
+}
+
+// recipeFailed reports whether a recipe status is terminal and unsuccessful.
+func recipeFailed(status string) bool {
+	switch status {
+	case "failed", "canceled", "cancelled", "error":
+		return true
+	}
+	return false
+}
+
+// failureDetails describes why a recipe failed using its status detail and
+// those of any of its child steps that did not complete.
+func failureDetails(recipe *compose.Recipe) []string {
+	details := []string{}
+	if len(recipe.StatusDetail) > 0 {
+		details = append(details, recipe.StatusDetail)
+	}
+	return append(details, stepFailures(recipe.Embedded.Recipes, "")...)
+}
+
+func stepFailures(steps []compose.Recipe, indent string) []string {
+	details := []string{}
+	for _, step := range steps {
+		if !recipeFailed(step.Status) {
+			continue
+		}
+		detail := fmt.Sprintf("%s- step '%s' %s", indent, step.Name, step.Status)
+		if len(step.StatusDetail) > 0 {
+			detail = fmt.Sprintf("%s: %s", detail, step.StatusDetail)
+		}
+		details = append(details, detail)
+		details = append(details, stepFailures(step.Embedded.Recipes, indent+"  ")...)
+	}
+	return details
+}
+
+// backoff returns the poll interval to use after the provided one, doubling
+// it up to maxPollInterval.
+func backoff(interval time.Duration) time.Duration {
+	if interval*2 > maxPollInterval {
+		return maxPollInterval
+	}
+	return interval * 2
+}
+
+// nextSleep returns how long to sleep before polling again. It never sleeps
+// past the deadline, so the final poll happens at the deadline.
+func nextSleep(interval, remaining time.Duration) time.Duration {
+	if remaining < interval {
+		if remaining < 0 {
+			return 0
+		}
+		return remaining
+	}
+	return interval
+}
+
+func (cxn *Connection) observeRecipeID(recipeID string) {
+	if cxn.observer != nil && len(cxn.runnerTarget) > 0 {
+		cxn.observer.RecipeID(cxn.runnerTarget, recipeID)
+	}
+}
+
+func (cxn *Connection) observeRecipe(recipe *compose.Recipe) {
+	if cxn.observer == nil || len(cxn.runnerTarget) == 0 {
+		return
+	}
+	cxn.observer.RecipeStatus(cxn.runnerTarget, recipe.ID, recipe.Status, currentStep(recipe))
+}
+
+// currentStep returns the name of the first child recipe of the provided
+// recipe that has not completed, if any.
+func currentStep(recipe *compose.Recipe) string {
+	for _, step := range recipe.Embedded.Recipes {
+		if step.Status != "complete" {
+			return step.Name
+		}
+	}
+	return ""
 }
 
 func filterTeams(teams []string, filterList []compose.Team) []string {
@@ -112,10 +348,14 @@ func teamListToMap(in []compose.Team) map[string]struct{} {
 	return out
 }
 
-func fetchAccountID(client *compose.Client) (string, error) {
-	account, errs := client.GetAccount()
+func (cxn *Connection) fetchAccountID() (string, error) {
+	var account *compose.Account
+	errs := cxn.call("GET", "/accounts", "", 1, func(c *compose.Client) (errs []error) {
+		account, errs = c.GetAccount()
+		return errs
+	})
 	if len(errs) != 0 {
-		return "", fmt.Errorf("Failed to get account id:\n%v", errs)
+		return "", &APIError{Op: "get account id", Errs: errs}
 	}
 
 	return account.ID, nil
@@ -130,6 +370,9 @@ func createClient(apiKey string, w io.Writer) (*compose.Client, error) {
 		// In the current version of gocomposeapi this is impossible
 		panic(err)
 	}
+	if w == nil {
+		return client, nil
+	}
 	return client.SetLogger(true, w), nil
 }
 
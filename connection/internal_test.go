@@ -0,0 +1,134 @@
+package connection
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+func TestFailureDetails(t *testing.T) {
+	recipe := &compose.Recipe{Status: "failed", StatusDetail: "Recipe failed"}
+	recipe.Embedded.Recipes = []compose.Recipe{
+		{Name: "Provision", Status: "complete"},
+		{Name: "Restore", Status: "failed", StatusDetail: "backup not found"},
+		{Name: "Cleanup", Status: "waiting"},
+	}
+	recipe.Embedded.Recipes[1].Embedded.Recipes = []compose.Recipe{
+		{Name: "Download", Status: "failed"},
+	}
+
+	expected := []string{
+		"Recipe failed",
+		"- step 'Restore' failed: backup not found",
+		"  - step 'Download' failed",
+	}
+	if actual := failureDetails(recipe); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected:\n%v\nSaw:\n%v", expected, actual)
+	}
+}
+
+var pollTests = []struct {
+	interval  time.Duration
+	remaining time.Duration
+	sleep     time.Duration
+	next      time.Duration
+}{
+	{
+		interval:  2 * time.Second,
+		remaining: time.Minute,
+		sleep:     2 * time.Second,
+		next:      4 * time.Second,
+	},
+	{
+		interval:  16 * time.Second,
+		remaining: 10 * time.Second,
+		sleep:     10 * time.Second,
+		next:      30 * time.Second,
+	},
+	{
+		interval:  30 * time.Second,
+		remaining: -time.Second,
+		sleep:     0,
+		next:      30 * time.Second,
+	},
+}
+
+func TestPollInterval(t *testing.T) {
+	for i, test := range pollTests {
+		if sleep := nextSleep(test.interval, test.remaining); sleep != test.sleep {
+			t.Errorf("Test #%d: Expected to sleep %v but saw %v", i, test.sleep, sleep)
+		}
+		if next := backoff(test.interval); next != test.next {
+			t.Errorf("Test #%d: Expected the next interval to be %v but saw %v", i, test.next, next)
+		}
+	}
+}
+
+// fakeClock advances only when it is slept on
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) pollClock() pollClock {
+	return pollClock{
+		now:   func() time.Time { return f.now },
+		sleep: func(d time.Duration) { f.now = f.now.Add(d) },
+	}
+}
+
+func TestPoll(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2017, 9, 30, 0, 0, 0, 0, time.UTC)}
+	start := clock.now
+	polls := []time.Duration{}
+	// Polls happen at 0s, 2s and, after a sleep clamped to the deadline,
+	// 5s. A recipe that finishes in that last interval is still seen.
+	done := poll(5*time.Second, clock.pollClock(), func(attempt int) bool {
+		polls = append(polls, clock.now.Sub(start))
+		return attempt == 3
+	})
+	expected := []time.Duration{0, 2 * time.Second, 5 * time.Second}
+	if !done || !reflect.DeepEqual(polls, expected) {
+		t.Errorf("Expected to finish after polling at %v, but saw %v at %v", expected, done, polls)
+	}
+
+	clock = &fakeClock{now: start}
+	polls = []time.Duration{}
+	done = poll(5*time.Second, clock.pollClock(), func(attempt int) bool {
+		polls = append(polls, clock.now.Sub(start))
+		return false
+	})
+	if done || !reflect.DeepEqual(polls, expected) {
+		t.Errorf("Expected to time out after polling at %v, but saw %v at %v", expected, done, polls)
+	}
+}
+
+func TestActiveRecipes(t *testing.T) {
+	recipes := []compose.Recipe{
+		{ID: "1", Status: "complete"},
+		{ID: "2", Status: "running"},
+		{ID: "3", Status: "failed"},
+		{ID: "4", Status: "waiting"},
+	}
+	active := activeRecipes(recipes)
+	if len(active) != 2 || active[0].ID != "2" || active[1].ID != "4" {
+		t.Errorf("Expected recipes 2 and 4 to be active, but saw: %+v", active)
+	}
+}
+
+func TestNotFoundByName(t *testing.T) {
+	for i, test := range []struct {
+		errs     []error
+		expected bool
+	}{
+		{errs: []error{errors.New("deployment not found: pg-01")}, expected: true},
+		{errs: []error{errors.New("unexpected status code: 404")}, expected: true},
+		{errs: []error{errors.New("503 Service Unavailable")}, expected: false},
+	} {
+		if actual := notFoundByName(test.errs); actual != test.expected {
+			t.Errorf("Test #%d: Expected %v but saw %v", i, test.expected, actual)
+		}
+	}
+}
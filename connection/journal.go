@@ -0,0 +1,119 @@
+package connection
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/benjdewan/pachelbel/errorqueue"
+	"github.com/benjdewan/pachelbel/journal"
+)
+
+// WithJournal returns a copy of the Connection that records the recipes it
+// starts in the provided journal until they finish. The copy shares all of
+// its other state with the original.
+func (cxn *Connection) WithJournal(j *journal.Journal) *Connection {
+	journaledCXN := *cxn
+	journaledCXN.journal = j
+	return &journaledCXN
+}
+
+// ResumePending waits on every recipe in the journal, in parallel, until
+// each finishes or its timeout elapses again. Finished recipes, successful
+// or not, are removed from the journal. The callback, if provided, is
+// invoked before waiting on each recipe.
+func (cxn *Connection) ResumePending(callback func(journal.Entry)) error {
+	var wg sync.WaitGroup
+	q := errorqueue.New()
+	for _, entry := range cxn.journal.Pending() {
+		if callback != nil {
+			callback(entry)
+		}
+		wg.Add(1)
+		go func(entry journal.Entry) {
+			defer wg.Done()
+			resumeCXN := cxn.ForRunner("Resuming", entry.Deployment)
+			if err := resumeCXN.wait(entry.Deployment, entry.RecipeID, entry.Timeout); err != nil {
+				q.Enqueue(err)
+			}
+		}(entry)
+	}
+	wg.Wait()
+	return q.Flush()
+}
+
+// assertIdle returns a *RecipeInFlightError if the journal holds an
+// unfinished recipe for the named deployment.
+func (cxn *Connection) assertIdle(name string) error {
+	if entry, ok := cxn.journal.Get(name); ok {
+		return &RecipeInFlightError{
+			Deployment: name,
+			RecipeID:   entry.RecipeID,
+			Operation:  entry.Operation,
+		}
+	}
+	return nil
+}
+
+// startRecipe records a recipe in the journal. Failing to do so must not
+// stop pachelbel waiting on a recipe that is already running, so errors
+// are only recorded as warnings.
+func (cxn *Connection) startRecipe(op, name, deploymentID, recipeID string, timeout float64) {
+	err := cxn.journal.Start(journal.Entry{
+		Deployment:   name,
+		DeploymentID: deploymentID,
+		Operation:    op,
+		RecipeID:     recipeID,
+		Timeout:      timeout,
+	})
+	if err != nil {
+		cxn.warn("Unable to record recipe %s for '%s' in the state file: %v", recipeID, name, err)
+	}
+}
+
+func (cxn *Connection) finishRecipe(name, recipeID string) {
+	if err := cxn.journal.Finish(name, recipeID); err != nil {
+		cxn.warn("Unable to remove recipe %s for '%s' from the state file: %v", recipeID, name, err)
+	}
+}
+
+// ForgetPending removes the unfinished recipe recorded for the named
+// deployment from the journal without waiting on it, for recipes that
+// can no longer be waited on.
+func (cxn *Connection) ForgetPending(name string) error {
+	entry, ok := cxn.journal.Get(name)
+	if !ok {
+		return fmt.Errorf("No unfinished recipe is recorded for '%s' in the state file", name)
+	}
+	return cxn.journal.Finish(name, entry.RecipeID)
+}
+
+// warnings collects problems that did not stop pachelbel, such as failing
+// to update the journal, so they can be reported once a command finishes.
+type warnings struct {
+	lock sync.Mutex
+	msgs []string
+}
+
+func (cxn *Connection) warn(format string, args ...interface{}) {
+	if cxn.warnings == nil {
+		return
+	}
+	cxn.warnings.lock.Lock()
+	defer cxn.warnings.lock.Unlock()
+	cxn.warnings.msgs = append(cxn.warnings.msgs, fmt.Sprintf(format, args...))
+}
+
+// TakeWarnings returns every warning recorded since it was last called
+func (cxn *Connection) TakeWarnings() []string {
+	if cxn.warnings == nil {
+		return []string{}
+	}
+	cxn.warnings.lock.Lock()
+	defer cxn.warnings.lock.Unlock()
+	msgs := cxn.warnings.msgs
+	cxn.warnings.msgs = nil
+	if msgs == nil {
+		return []string{}
+	}
+	return msgs
+}
@@ -0,0 +1,60 @@
+package connection
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/benjdewan/pachelbel/journal"
+)
+
+func TestJournalWarnings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pachelbel-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	j, err := journal.Open(filepath.Join(dir, "missing", "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cxn := (&Connection{warnings: &warnings{}}).WithJournal(j).ForRunner("Resizing", "pg-01")
+	cxn.startRecipe(journal.OpResize, "pg-01", "id-01", "r1", 900)
+
+	if warnings := cxn.TakeWarnings(); len(warnings) != 1 {
+		t.Errorf("Expected one warning for the failed journal write, but saw %v", warnings)
+	}
+	if warnings := cxn.TakeWarnings(); len(warnings) != 0 {
+		t.Errorf("Expected warnings to only be returned once, but saw %v", warnings)
+	}
+}
+
+func TestForgetPending(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pachelbel-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	j, err := journal.Open(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cxn := (&Connection{warnings: &warnings{}}).WithJournal(j)
+	cxn.startRecipe(journal.OpDeprovision, "pg-01", "id-01", "r1", 900)
+
+	if err = cxn.assertIdle("pg-01"); err == nil {
+		t.Fatalf("Expected 'pg-01' to have a recipe in flight")
+	}
+	if err = cxn.ForgetPending("pg-01"); err != nil {
+		t.Fatal(err)
+	}
+	if err = cxn.assertIdle("pg-01"); err != nil {
+		t.Errorf("Expected 'pg-01' to be idle once forgotten, but saw: %v", err)
+	}
+	if err = cxn.ForgetPending("pg-01"); err == nil {
+		t.Errorf("Expected an error forgetting a deployment with no recipe in flight")
+	}
+}
@@ -0,0 +1,72 @@
+package connection
+
+import (
+	"sort"
+	"time"
+
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+// Recipes returns every recipe run on the deployment with the provided ID,
+// newest first
+func (cxn *Connection) Recipes(id, name string) ([]compose.Recipe, error) {
+	var recipes *[]compose.Recipe
+	errs := cxn.call("GET", "/deployments/"+id+"/recipes", name, 1, func(c *compose.Client) (errs []error) {
+		recipes, errs = c.GetRecipesForDeployment(id)
+		return errs
+	})
+	if len(errs) != 0 {
+		return nil, &APIError{Deployment: name, Op: "list the recipes of", Errs: errs}
+	} else if recipes == nil {
+		return []compose.Recipe{}, nil
+	}
+	return newestFirst(*recipes), nil
+}
+
+// WaitRecipe waits on a recipe, wherever it was started, until it finishes
+// or its timeout elapses. Its status is reported to the Connection's
+// observer as it changes.
+func (cxn *Connection) WaitRecipe(r RecipeWait) error {
+	recipeID := r.GetRecipeID()
+	var recipe *compose.Recipe
+	errs := cxn.call("GET", "/recipes/"+recipeID, "", 1, func(c *compose.Client) (errs []error) {
+		recipe, errs = c.GetRecipe(recipeID)
+		return errs
+	})
+	if len(errs) != 0 || recipe == nil {
+		return &APIError{Deployment: recipeID, Op: "look up the recipe", Errs: errs}
+	}
+
+	// Recipes started by pachelbel are journaled by deployment name, so use
+	// it to attribute the wait where possible.
+	name := recipe.DeploymentID
+	var deployment *compose.Deployment
+	errs = cxn.call("GET", "/deployments/"+recipe.DeploymentID, "", 1, func(c *compose.Client) (errs []error) {
+		deployment, errs = c.GetDeployment(recipe.DeploymentID)
+		return errs
+	})
+	if len(errs) == 0 && deployment != nil {
+		name = deployment.Name
+	}
+	return cxn.wait(name, recipeID, r.GetTimeout())
+}
+
+// RecipeDuration is how long a recipe ran for, or has been running for if
+// it has not finished
+func RecipeDuration(recipe compose.Recipe, now time.Time) time.Duration {
+	end := recipe.UpdatedAt
+	if len(activeRecipes([]compose.Recipe{recipe})) != 0 {
+		end = now
+	}
+	return end.Sub(recipe.CreatedAt).Round(time.Second)
+}
+
+// newestFirst returns a copy of the provided recipes sorted newest first
+func newestFirst(recipes []compose.Recipe) []compose.Recipe {
+	sorted := make([]compose.Recipe, len(recipes))
+	copy(sorted, recipes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+	return sorted
+}
@@ -0,0 +1,44 @@
+package connection
+
+import (
+	"testing"
+	"time"
+
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+func TestNewestFirst(t *testing.T) {
+	now := time.Date(2017, time.September, 30, 0, 0, 0, 0, time.UTC)
+	recipes := []compose.Recipe{
+		{ID: "old", CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: "new", CreatedAt: now.Add(-1 * time.Hour)},
+		{ID: "mid", CreatedAt: now.Add(-2 * time.Hour)},
+	}
+	sorted := newestFirst(recipes)
+	if len(sorted) != 3 || sorted[0].ID != "new" || sorted[1].ID != "mid" || sorted[2].ID != "old" {
+		t.Errorf("Expected the recipes newest first, but saw %+v", sorted)
+	}
+	if recipes[0].ID != "old" {
+		t.Error("Expected the provided recipes to be left unsorted")
+	}
+}
+
+func TestRecipeDuration(t *testing.T) {
+	now := time.Date(2017, time.September, 30, 0, 0, 0, 0, time.UTC)
+	created := now.Add(-10 * time.Minute)
+	for i, test := range recipeDurationTests {
+		recipe := compose.Recipe{Status: test.status, CreatedAt: created, UpdatedAt: created.Add(90 * time.Second)}
+		if actual := RecipeDuration(recipe, now); actual != test.expected {
+			t.Errorf("Test #%d: Expected a '%s' recipe to have run for %v but saw %v", i, test.status, test.expected, actual)
+		}
+	}
+}
+
+var recipeDurationTests = []struct {
+	status   string
+	expected time.Duration
+}{
+	{status: "complete", expected: 90 * time.Second},
+	{status: "failed", expected: 90 * time.Second},
+	{status: "running", expected: 10 * time.Minute},
+}
@@ -0,0 +1,146 @@
+package connection
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+)
+
+const redacted = "[REDACTED]"
+
+var redactions = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{
+		pattern:     regexp.MustCompile(`(?im)^(Authorization:\s*)(\S+\s+)?\S+`),
+		replacement: "${1}${2}" + redacted,
+	},
+	{
+		pattern:     regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^:/\s"'@]*:)[^@\s"'/]+@`),
+		replacement: "${1}" + redacted + "@",
+	},
+	{
+		pattern:     regexp.MustCompile(`("(?:ca_certificate_base64|password)"\s*:\s*")(?:[^"\\]|\\.)*"`),
+		replacement: "${1}" + redacted + `"`,
+	},
+	{
+		pattern:     regexp.MustCompile(`(?i)(password=)[^\s"'&\\]+`),
+		replacement: "${1}" + redacted,
+	},
+}
+
+var connectionKeys = [][]byte{[]byte(`"connection_strings"`), []byte(`"connection"`)}
+
+// redactingWriter masks credentials in Compose API logs before they are
+// written to the underlying writer. gocomposeapi logs every request and
+// response using a single Write() call, so each call is redacted as a whole.
+type redactingWriter struct {
+	w io.Writer
+}
+
+func newRedactingWriter(w io.Writer) io.Writer {
+	return &redactingWriter{w: w}
+}
+
+func (r *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := r.w.Write(redact(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func redact(p []byte) []byte {
+	out := redactConnectionBlocks(p)
+	for _, redaction := range redactions {
+		out = redaction.pattern.ReplaceAll(out, []byte(redaction.replacement))
+	}
+	return out
+}
+
+// redactConnectionBlocks replaces the value of every "connection_strings"
+// or "connection" JSON object with a placeholder. Regular expressions cannot
+// match nested braces, so this walks the JSON by hand. An object that is
+// not closed is redacted to the end of p.
+func redactConnectionBlocks(p []byte) []byte {
+	out := []byte{}
+	for {
+		start, end := nextConnectionBlock(p)
+		if start < 0 {
+			return append(out, p...)
+		}
+		out = append(out, p[:start]...)
+		out = append(out, []byte(`"`+redacted+`"`)...)
+		p = p[end:]
+	}
+}
+
+func nextConnectionBlock(p []byte) (int, int) {
+	offset := 0
+	for {
+		key, index := firstKey(p[offset:])
+		if index < 0 {
+			return -1, -1
+		}
+		i := offset + index + len(key)
+		i += leadingSpace(p[i:])
+		if i >= len(p) || p[i] != ':' {
+			offset = i
+			continue
+		}
+		i++
+		i += leadingSpace(p[i:])
+		if i >= len(p) || p[i] != '{' {
+			offset = i
+			continue
+		}
+		if end := matchBrace(p, i); end > 0 {
+			return i, end
+		}
+		// A block cut off by the end of the write may still hold
+		// credentials, so everything after its start is redacted
+		return i, len(p)
+	}
+}
+
+func firstKey(p []byte) ([]byte, int) {
+	var (
+		first    []byte
+		firstIdx = -1
+	)
+	for _, key := range connectionKeys {
+		if i := bytes.Index(p, key); i >= 0 && (firstIdx < 0 || i < firstIdx) {
+			first, firstIdx = key, i
+		}
+	}
+	return first, firstIdx
+}
+
+func leadingSpace(p []byte) int {
+	return len(p) - len(bytes.TrimLeft(p, " \t\r\n"))
+}
+
+// matchBrace returns the index after the brace closing the one at start, or
+// -1 if it is never closed.
+func matchBrace(p []byte, start int) int {
+	depth, inString, escaped := 0, false, false
+	for i := start; i < len(p); i++ {
+		switch c := p[i]; {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
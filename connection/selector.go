@@ -0,0 +1,141 @@
+package connection
+
+import (
+	"path"
+	"regexp"
+	"sort"
+
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+// Selector picks deployments by name and by their attributes. A deployment
+// is selected if it matches any of the names, globs, patterns or declared
+// deployments, and it also matches every attribute filter that is set. If
+// none of those are set, the filters only select from every deployment
+// when All is set.
+type Selector struct {
+	// Names are exact deployment names or IDs
+	Names []string
+	// Declared are the names of the deployments declared in configuration.
+	// Once Configured is set they are the only deployments configuration
+	// can select, so configuration declaring none selects nothing.
+	Declared   []string
+	Configured bool
+	// Globs are shell patterns, as used by path.Match, matched against
+	// deployment names
+	Globs []string
+	// Patterns are regular expressions matched against deployment names
+	Patterns []*regexp.Regexp
+
+	// Types limits the selection to deployments of these types
+	Types []string
+	// Notes limits the selection to deployments with matching notes
+	Notes *regexp.Regexp
+	// Clusters limits the selection to deployments declared in one of these
+	// clusters. The Compose API does not report which cluster a deployment
+	// runs in, so DeclaredClusters must map deployment names to the cluster
+	// they are declared in.
+	Clusters         []string
+	DeclaredClusters map[string]string
+
+	// All lets the attribute filters alone select from every deployment
+	// in the account
+	All bool
+}
+
+// Empty returns true if the Selector has nothing to select deployments by
+func (s Selector) Empty() bool {
+	return !s.named() && !(s.All && s.Filtered())
+}
+
+// Filtered returns true if any attribute filter is set
+func (s Selector) Filtered() bool {
+	return len(s.Types) != 0 || s.Notes != nil || len(s.Clusters) != 0
+}
+
+func (s Selector) named() bool {
+	return s.Configured || len(s.Names) != 0 || len(s.Declared) != 0 ||
+		len(s.Globs) != 0 || len(s.Patterns) != 0
+}
+
+// Select returns the deployments that match the Selector, sorted by name
+func (s Selector) Select(deployments []compose.Deployment) []compose.Deployment {
+	selected := []compose.Deployment{}
+	if s.Empty() {
+		return selected
+	}
+	for _, deployment := range deployments {
+		if s.matchesName(deployment) && s.matchesFilters(deployment) {
+			selected = append(selected, deployment)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Name < selected[j].Name
+	})
+	return selected
+}
+
+func (s Selector) matchesName(deployment compose.Deployment) bool {
+	if !s.named() {
+		return s.All
+	}
+	for _, name := range s.Names {
+		if name == deployment.Name || name == deployment.ID {
+			return true
+		}
+	}
+	if contains(s.Declared, deployment.Name) {
+		return true
+	}
+	for _, glob := range s.Globs {
+		if ok, err := path.Match(glob, deployment.Name); ok && err == nil {
+			return true
+		}
+	}
+	for _, pattern := range s.Patterns {
+		if pattern.MatchString(deployment.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Selector) matchesFilters(deployment compose.Deployment) bool {
+	if len(s.Types) > 0 && !contains(s.Types, deployment.Type) {
+		return false
+	}
+	if s.Notes != nil && !s.Notes.MatchString(deployment.Notes) {
+		return false
+	}
+	if len(s.Clusters) > 0 {
+		cluster, ok := s.DeclaredClusters[deployment.Name]
+		if !ok || !contains(s.Clusters, cluster) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, str string) bool {
+	for _, item := range list {
+		if item == str {
+			return true
+		}
+	}
+	return false
+}
+
+// Deployments returns every deployment in the Compose account
+func (cxn *Connection) Deployments() ([]compose.Deployment, error) {
+	var deployments *[]compose.Deployment
+	errs := cxn.call("GET", "/deployments", "", 1, func(c *compose.Client) (errs []error) {
+		deployments, errs = c.GetDeployments()
+		return errs
+	})
+	if len(errs) != 0 {
+		return nil, &APIError{Op: "list deployments", Errs: errs}
+	} else if deployments == nil {
+		return []compose.Deployment{}, nil
+	}
+	return *deployments, nil
+}
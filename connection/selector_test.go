@@ -0,0 +1,77 @@
+package connection
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+var selectorDeployments = []compose.Deployment{
+	{ID: "1", Name: "pg-staging-01", Type: "postgresql", Notes: "owner: search"},
+	{ID: "2", Name: "pg-prod-01", Type: "postgresql", Notes: "owner: billing"},
+	{ID: "3", Name: "redis-staging-01", Type: "redis", Notes: "owner: search"},
+	{ID: "4", Name: "es-staging-01", Type: "elastic_search"},
+}
+
+func TestSelect(t *testing.T) {
+	for i, test := range selectTests {
+		names := []string{}
+		for _, deployment := range test.selector.Select(selectorDeployments) {
+			names = append(names, deployment.Name)
+		}
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("Test #%d: Expected %v but saw %v", i, test.expected, names)
+		}
+	}
+}
+
+var selectTests = []struct {
+	selector Selector
+	expected []string
+}{
+	{
+		selector: Selector{},
+		expected: []string{},
+	},
+	{
+		selector: Selector{Names: []string{"pg-prod-01", "4", "missing"}},
+		expected: []string{"es-staging-01", "pg-prod-01"},
+	},
+	{
+		selector: Selector{Globs: []string{"*-staging-*"}},
+		expected: []string{"es-staging-01", "pg-staging-01", "redis-staging-01"},
+	},
+	{
+		selector: Selector{Patterns: []*regexp.Regexp{regexp.MustCompile("^(pg|es)-staging")}},
+		expected: []string{"es-staging-01", "pg-staging-01"},
+	},
+	{
+		selector: Selector{Globs: []string{"*-staging-*"}, Types: []string{"postgresql", "redis"}},
+		expected: []string{"pg-staging-01", "redis-staging-01"},
+	},
+	{
+		selector: Selector{Notes: regexp.MustCompile("owner: search"), All: true},
+		expected: []string{"pg-staging-01", "redis-staging-01"},
+	},
+	// Filters alone never select from the whole account without All
+	{
+		selector: Selector{Notes: regexp.MustCompile("owner: search")},
+		expected: []string{},
+	},
+	{
+		selector: Selector{
+			Declared:         []string{"pg-staging-01", "pg-prod-01"},
+			Configured:       true,
+			Clusters:         []string{"staging"},
+			DeclaredClusters: map[string]string{"pg-staging-01": "staging", "pg-prod-01": "prod"},
+		},
+		expected: []string{"pg-staging-01"},
+	},
+	// Configuration that declares nothing selects nothing, even with All
+	{
+		selector: Selector{Configured: true, Types: []string{"postgresql"}, All: true},
+		expected: []string{},
+	},
+}
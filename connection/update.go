@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	compose "github.com/benjdewan/gocomposeapi"
+	"github.com/benjdewan/pachelbel/journal"
 )
 
 // UpdateScaling does nothing if the provided scaling is 0, but
@@ -11,19 +12,32 @@ import (
 // then wait on the returned recipe until it completes or the
 // timeout is exceeded.
 func (cxn *Connection) UpdateScaling(deployment Deployment) error {
-	if deployment.GetScaling() <= 1 {
+	if deployment.GetScaling() == 0 {
 		return nil
+	} else if err := cxn.assertIdle(deployment.GetName()); err != nil {
+		return err
+	} else if err := cxn.awaitActiveRecipes(deployment.GetName(), deployment.GetID(), deployment.GetTimeout()); err != nil {
+		return err
 	}
 
-	recipe, errs := cxn.client.SetScalings(compose.ScalingsParams{
-		DeploymentID: deployment.GetID(),
-		Units:        deployment.GetScaling(),
+	return cxn.resize(deployment.GetID(), deployment.GetName(), deployment.GetScaling(), deployment.GetTimeout())
+}
+
+func (cxn *Connection) resize(id, name string, units int, timeout float64) error {
+	var recipe *compose.Recipe
+	errs := cxn.call("POST", "/deployments/"+id+"/scalings", name, 1, func(c *compose.Client) (errs []error) {
+		recipe, errs = c.SetScalings(compose.ScalingsParams{
+			DeploymentID: id,
+			Units:        units,
+		})
+		return errs
 	})
 	if len(errs) != 0 {
-		return fmt.Errorf("Unable to resize '%s':\n%v", deployment.GetName(), errs)
+		return &APIError{Deployment: name, Op: "resize", Errs: errs}
 	}
 
-	return cxn.wait(recipe.ID, deployment.GetTimeout())
+	cxn.startRecipe(journal.OpResize, name, id, recipe.ID, timeout)
+	return cxn.wait(name, recipe.ID, timeout)
 }
 
 // UpdateNotes does nothing if the deployment notes field is blank,
@@ -34,12 +48,19 @@ func (cxn *Connection) UpdateNotes(deployment Deployment) error {
 		return nil
 	}
 
-	_, errs := cxn.client.PatchDeployment(compose.PatchDeploymentParams{
-		DeploymentID: deployment.GetID(),
-		Notes:        deployment.GetNotes(),
+	return cxn.setNotes(deployment.GetID(), deployment.GetName(), deployment.GetNotes())
+}
+
+func (cxn *Connection) setNotes(id, name, notes string) error {
+	errs := cxn.call("PATCH", "/deployments/"+id, name, 1, func(c *compose.Client) []error {
+		_, errs := c.PatchDeployment(compose.PatchDeploymentParams{
+			DeploymentID: id,
+			Notes:        notes,
+		})
+		return errs
 	})
 	if len(errs) != 0 {
-		return fmt.Errorf("Unable to update notes on '%s':\n%v", deployment.GetName(), errs)
+		return &APIError{Deployment: name, Op: "update notes on", Errs: errs}
 	}
 	return nil
 }
@@ -50,12 +71,24 @@ func (cxn *Connection) UpdateNotes(deployment Deployment) error {
 func (cxn *Connection) UpdateVersion(deployment Deployment) error {
 	if len(deployment.GetVersion()) == 0 {
 		return nil
+	} else if err := cxn.assertIdle(deployment.GetName()); err != nil {
+		return err
+	} else if err := cxn.awaitActiveRecipes(deployment.GetName(), deployment.GetID(), deployment.GetTimeout()); err != nil {
+		return err
 	}
 
-	recipe, errs := cxn.client.UpdateVersion(deployment.GetID(), deployment.GetVersion())
+	var recipe *compose.Recipe
+	errs := cxn.call("PATCH", "/deployments/"+deployment.GetID()+"/versions", deployment.GetName(), 1, func(c *compose.Client) (errs []error) {
+		recipe, errs = c.UpdateVersion(deployment.GetID(), deployment.GetVersion())
+		return errs
+	})
 	if len(errs) != 0 {
-		return fmt.Errorf("Unable to upgrade %s to version %s:\n%v", deployment.GetName(), deployment.GetVersion(), errs)
+		return &APIError{
+			Deployment: deployment.GetName(),
+			Op:         fmt.Sprintf("upgrade to version %s", deployment.GetVersion()),
+			Errs:       errs,
+		}
 	}
-	return cxn.wait(recipe.ID, deployment.GetTimeout())
-
+	cxn.startRecipe(journal.OpUpgrade, deployment.GetName(), deployment.GetID(), recipe.ID, deployment.GetTimeout())
+	return cxn.wait(deployment.GetName(), recipe.ID, deployment.GetTimeout())
 }
@@ -0,0 +1,159 @@
+package connection
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	compose "github.com/benjdewan/gocomposeapi"
+	"github.com/benjdewan/pachelbel/journal"
+)
+
+// WhitelistEntry is a CIDR range allowed to connect to a deployment
+type WhitelistEntry struct {
+	// ID is set for entries that exist in Compose
+	ID          string
+	CIDR        string
+	Description string
+}
+
+// Whitelist returns the IP whitelist of the deployment with the provided ID
+func (cxn *Connection) Whitelist(id, name string) ([]WhitelistEntry, error) {
+	var whitelist *[]compose.DeploymentWhitelist
+	errs := cxn.call("GET", "/deployments/"+id+"/whitelist", name, 1, func(c *compose.Client) (errs []error) {
+		whitelist, errs = c.GetWhitelistForDeployment(id)
+		return errs
+	})
+	if len(errs) != 0 {
+		return nil, &APIError{Deployment: name, Op: "get the whitelist for", Errs: errs}
+	}
+
+	entries := []WhitelistEntry{}
+	if whitelist == nil {
+		return entries, nil
+	}
+	for _, entry := range *whitelist {
+		entries = append(entries, WhitelistEntry{
+			ID:          entry.ID,
+			CIDR:        entry.IP,
+			Description: entry.Description,
+		})
+	}
+	return entries, nil
+}
+
+// AddWhitelistEntry adds a CIDR range to a deployment's whitelist and waits
+// on the resulting recipe
+func (cxn *Connection) AddWhitelistEntry(id string, deployment Deployment, entry WhitelistEntry) error {
+	var recipe *compose.Recipe
+	errs := cxn.call("POST", "/deployments/"+id+"/whitelist", deployment.GetName(), 1, func(c *compose.Client) (errs []error) {
+		recipe, errs = c.CreateDeploymentWhitelist(id, compose.DeploymentWhitelistParams{
+			IP:          entry.CIDR,
+			Description: entry.Description,
+		})
+		return errs
+	})
+	if len(errs) != 0 {
+		return &APIError{
+			Deployment: deployment.GetName(),
+			Op:         fmt.Sprintf("whitelist %s on", entry.CIDR),
+			Errs:       errs,
+		}
+	}
+	cxn.startRecipe(journal.OpWhitelist, deployment.GetName(), id, recipe.ID, deployment.GetTimeout())
+	return cxn.wait(deployment.GetName(), recipe.ID, deployment.GetTimeout())
+}
+
+// RemoveWhitelistEntry removes an existing entry from a deployment's
+// whitelist and waits on the resulting recipe
+func (cxn *Connection) RemoveWhitelistEntry(id string, deployment Deployment, entry WhitelistEntry) error {
+	var recipe *compose.Recipe
+	errs := cxn.call("DELETE", "/deployments/"+id+"/whitelist/"+entry.ID, deployment.GetName(), 1, func(c *compose.Client) (errs []error) {
+		recipe, errs = c.DeleteDeploymentWhitelist(id, entry.ID)
+		return errs
+	})
+	if len(errs) != 0 {
+		return &APIError{
+			Deployment: deployment.GetName(),
+			Op:         fmt.Sprintf("remove %s from the whitelist of", entry.CIDR),
+			Errs:       errs,
+		}
+	}
+	cxn.startRecipe(journal.OpWhitelist, deployment.GetName(), id, recipe.ID, deployment.GetTimeout())
+	return cxn.wait(deployment.GetName(), recipe.ID, deployment.GetTimeout())
+}
+
+// UpdateWhitelist does nothing if the deployment does not manage its
+// whitelist. Otherwise it adds every missing entry to the whitelist of the
+// deployment with the provided ID, and then removes any others.
+func (cxn *Connection) UpdateWhitelist(id string, deployment Deployment) error {
+	desired := deployment.GetWhitelist()
+	if desired == nil {
+		return nil
+	} else if err := cxn.assertIdle(deployment.GetName()); err != nil {
+		return err
+	} else if err := cxn.awaitActiveRecipes(deployment.GetName(), id, deployment.GetTimeout()); err != nil {
+		return err
+	}
+
+	existing, err := cxn.Whitelist(id, deployment.GetName())
+	if err != nil {
+		return err
+	}
+	add, remove := DiffWhitelist(desired, existing)
+	for _, entry := range add {
+		if err := cxn.AddWhitelistEntry(id, deployment, entry); err != nil {
+			return err
+		}
+	}
+	for _, entry := range remove {
+		if err := cxn.RemoveWhitelistEntry(id, deployment, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffWhitelist returns the entries that must be added to and removed from
+// an existing whitelist to match the desired one. Entries are compared by
+// their normalized CIDR range, and addresses without a prefix length are
+// treated as a single host. Descriptions are only set on added entries.
+func DiffWhitelist(desired, existing []WhitelistEntry) ([]WhitelistEntry, []WhitelistEntry) {
+	existingByCIDR := make(map[string]WhitelistEntry)
+	for _, entry := range existing {
+		existingByCIDR[normalizeCIDR(entry.CIDR)] = entry
+	}
+
+	add := []WhitelistEntry{}
+	wanted := make(map[string]struct{})
+	for _, entry := range desired {
+		cidr := normalizeCIDR(entry.CIDR)
+		wanted[cidr] = struct{}{}
+		if _, ok := existingByCIDR[cidr]; !ok {
+			add = append(add, entry)
+		}
+	}
+
+	remove := []WhitelistEntry{}
+	for cidr, entry := range existingByCIDR {
+		if _, ok := wanted[cidr]; !ok {
+			remove = append(remove, entry)
+		}
+	}
+	sort.Slice(remove, func(i, j int) bool {
+		return remove[i].CIDR < remove[j].CIDR
+	})
+	return add, remove
+}
+
+func normalizeCIDR(cidr string) string {
+	if _, ipNet, err := net.ParseCIDR(cidr); err == nil {
+		return ipNet.String()
+	} else if ip := net.ParseIP(cidr); ip != nil {
+		if ip.To4() != nil {
+			return ip.String() + "/32"
+		}
+		return ip.String() + "/128"
+	}
+	return cidr
+}
@@ -0,0 +1,27 @@
+package connection
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffWhitelist(t *testing.T) {
+	desired := []WhitelistEntry{
+		{CIDR: "10.0.0.1/24", Description: "office"},
+		{CIDR: "192.168.1.1/32", Description: "vpn"},
+		{CIDR: "2001:db8::/32", Description: "ipv6"},
+	}
+	existing := []WhitelistEntry{
+		{ID: "1", CIDR: "10.0.0.0/24", Description: "renamed"},
+		{ID: "2", CIDR: "192.168.1.1"},
+		{ID: "3", CIDR: "172.16.0.0/12"},
+	}
+
+	add, remove := DiffWhitelist(desired, existing)
+	if expected := desired[2:]; !reflect.DeepEqual(expected, add) {
+		t.Errorf("Expected to add %v but saw %v", expected, add)
+	}
+	if expected := existing[2:]; !reflect.DeepEqual(expected, remove) {
+		t.Errorf("Expected to remove %v but saw %v", expected, remove)
+	}
+}
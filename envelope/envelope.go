@@ -0,0 +1,277 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	pemType  = "PACHELBEL ENCRYPTED OUTPUT"
+	version  = 1
+	keySize  = 32
+	saltSize = 16
+	// scrypt parameters as recommended for interactive logins in 2017
+	scryptLogN = 15
+	scryptR    = 8
+	scryptP    = 1
+)
+
+// Envelope encrypts and decrypts data using a passphrase, a list of x25519
+// recipient public keys, or both. The data is encrypted with a random key
+// which is then wrapped once for the passphrase and once for every recipient,
+// so any one of them is enough to decrypt it.
+type Envelope struct {
+	passphrase []byte
+	recipients []*[keySize]byte
+	identity   *[keySize]byte
+}
+
+type sealedEnvelope struct {
+	Version    int            `json:"version"`
+	Passphrase *passphraseKey `json:"passphrase,omitempty"`
+	Recipients [][]byte       `json:"recipients,omitempty"`
+	Nonce      []byte         `json:"nonce"`
+	Body       []byte         `json:"body"`
+}
+
+type passphraseKey struct {
+	LogN  int    `json:"log_n"`
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Key   []byte `json:"key"`
+}
+
+// New returns an empty Envelope. At least one passphrase, recipient or
+// identity must be added before it can be used.
+func New() *Envelope {
+	return &Envelope{recipients: [](*[keySize]byte){}}
+}
+
+// SetPassphrase sets the passphrase used to seal and open data
+func (e *Envelope) SetPassphrase(passphrase []byte) *Envelope {
+	e.passphrase = passphrase
+	return e
+}
+
+// AddRecipient adds a base64 encoded x25519 public key that will be able to
+// open sealed data using the matching private key.
+func (e *Envelope) AddRecipient(encodedKey string) error {
+	key, err := decodeKey(encodedKey)
+	if err != nil {
+		return fmt.Errorf("Invalid recipient public key '%s': %v", encodedKey, err)
+	}
+	e.recipients = append(e.recipients, key)
+	return nil
+}
+
+// SetIdentity sets the base64 encoded x25519 private key used to open data
+// sealed for the matching public key.
+func (e *Envelope) SetIdentity(encodedKey string) error {
+	key, err := decodeKey(encodedKey)
+	if err != nil {
+		return fmt.Errorf("Invalid private key: %v", err)
+	}
+	e.identity = key
+	return nil
+}
+
+// CanSeal returns true if the Envelope has a passphrase or at least one
+// recipient to seal data for.
+func (e *Envelope) CanSeal() bool {
+	return len(e.passphrase) > 0 || len(e.recipients) > 0
+}
+
+// CanOpen returns true if the Envelope has a passphrase or an identity to
+// open sealed data with.
+func (e *Envelope) CanOpen() bool {
+	return len(e.passphrase) > 0 || e.identity != nil
+}
+
+// IsSealed returns true if data looks like the output of Seal()
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN "+pemType+"-----"))
+}
+
+// Seal encrypts the plaintext for the Envelope's passphrase and recipients
+// and returns it PEM encoded.
+func (e *Envelope) Seal(plaintext []byte) ([]byte, error) {
+	if !e.CanSeal() {
+		return nil, errors.New("A passphrase or at least one recipient is required to encrypt output")
+	}
+	dataKey, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := sealedEnvelope{
+		Version:    version,
+		Recipients: [][]byte{},
+		Nonce:      nonce[:],
+		Body:       secretbox.Seal(nil, plaintext, nonce, dataKey),
+	}
+	if len(e.passphrase) > 0 {
+		if sealed.Passphrase, err = wrapWithPassphrase(dataKey, e.passphrase); err != nil {
+			return nil, err
+		}
+	}
+	for _, recipient := range e.recipients {
+		wrapped, err := box.SealAnonymous(nil, dataKey[:], recipient, rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		sealed.Recipients = append(sealed.Recipients, wrapped)
+	}
+
+	body, err := json.Marshal(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: body}), nil
+}
+
+// Open decrypts data created by Seal() using the Envelope's passphrase or
+// identity.
+func (e *Envelope) Open(data []byte) ([]byte, error) {
+	if !e.CanOpen() {
+		return nil, errors.New("A passphrase or private key is required to decrypt output")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemType {
+		return nil, errors.New("The provided data is not encrypted pachelbel output")
+	}
+	var sealed sealedEnvelope
+	if err := json.Unmarshal(block.Bytes, &sealed); err != nil {
+		return nil, fmt.Errorf("Unable to parse encrypted output: %v", err)
+	}
+	if sealed.Version != version {
+		return nil, fmt.Errorf("Unsupported encrypted output version '%d'", sealed.Version)
+	}
+
+	dataKey, err := e.unwrap(sealed)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], sealed.Nonce)
+	plaintext, ok := secretbox.Open(nil, sealed.Body, &nonce, dataKey)
+	if !ok {
+		return nil, errors.New("Encrypted output has been corrupted or tampered with")
+	}
+	return plaintext, nil
+}
+
+func (e *Envelope) unwrap(sealed sealedEnvelope) (*[keySize]byte, error) {
+	if len(e.passphrase) > 0 && sealed.Passphrase != nil {
+		if dataKey, err := unwrapWithPassphrase(sealed.Passphrase, e.passphrase); err == nil {
+			return dataKey, nil
+		}
+	}
+	if e.identity != nil {
+		public := publicKey(e.identity)
+		for _, wrapped := range sealed.Recipients {
+			if key, ok := box.OpenAnonymous(nil, wrapped, public, e.identity); ok && len(key) == keySize {
+				return toKey(key), nil
+			}
+		}
+	}
+	return nil, errors.New("None of the provided passphrases or keys can decrypt this output")
+}
+
+func wrapWithPassphrase(dataKey *[keySize]byte, passphrase []byte) (*passphraseKey, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	wrappingKey, err := deriveKey(passphrase, salt, scryptLogN)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomNonce()
+	if err != nil {
+		return nil, err
+	}
+	return &passphraseKey{
+		LogN:  scryptLogN,
+		Salt:  salt,
+		Nonce: nonce[:],
+		Key:   secretbox.Seal(nil, dataKey[:], nonce, wrappingKey),
+	}, nil
+}
+
+func unwrapWithPassphrase(wrapped *passphraseKey, passphrase []byte) (*[keySize]byte, error) {
+	wrappingKey, err := deriveKey(passphrase, wrapped.Salt, wrapped.LogN)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], wrapped.Nonce)
+	key, ok := secretbox.Open(nil, wrapped.Key, &nonce, wrappingKey)
+	if !ok || len(key) != keySize {
+		return nil, errors.New("Incorrect passphrase")
+	}
+	return toKey(key), nil
+}
+
+func deriveKey(passphrase, salt []byte, logN int) (*[keySize]byte, error) {
+	if logN < 10 || logN > 22 {
+		return nil, fmt.Errorf("Unsupported scrypt work factor '%d'", logN)
+	}
+	key, err := scrypt.Key(passphrase, salt, 1<<uint(logN), scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	return toKey(key), nil
+}
+
+func publicKey(private *[keySize]byte) *[keySize]byte {
+	var public [keySize]byte
+	curve25519.ScalarBaseMult(&public, private)
+	return &public
+}
+
+func decodeKey(encodedKey string) (*[keySize]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, err
+	} else if len(key) != keySize {
+		return nil, fmt.Errorf("expected a %d byte key, but saw %d bytes", keySize, len(key))
+	}
+	return toKey(key), nil
+}
+
+func randomKey() (*[keySize]byte, error) {
+	var key [keySize]byte
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func randomNonce() (*[24]byte, error) {
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+	return &nonce, nil
+}
+
+func toKey(raw []byte) *[keySize]byte {
+	var key [keySize]byte
+	copy(key[:], raw)
+	return &key
+}
@@ -0,0 +1,95 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+var plaintext = []byte("postgres-benjdewan-01:\n  type: postgresql\n")
+
+func TestSealWithPassphrase(t *testing.T) {
+	sealed, err := New().SetPassphrase([]byte("correct horse")).Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) {
+		t.Errorf("Expected sealed output to be recognised as sealed:\n%s", sealed)
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Errorf("Sealed output contains the plaintext:\n%s", sealed)
+	}
+
+	actual, err := New().SetPassphrase([]byte("correct horse")).Open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(actual, plaintext) {
+		t.Errorf("Expected '%s' but saw '%s'", plaintext, actual)
+	}
+
+	if _, err := New().SetPassphrase([]byte("battery staple")).Open(sealed); err == nil {
+		t.Error("Expected an incorrect passphrase to fail")
+	}
+}
+
+func TestSealForRecipients(t *testing.T) {
+	publicA, privateA := generateKey(t)
+	publicB, privateB := generateKey(t)
+	_, privateC := generateKey(t)
+
+	e := New()
+	for _, public := range []string{publicA, publicB} {
+		if err := e.AddRecipient(public); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sealed, err := e.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, test := range []struct {
+		private string
+		valid   bool
+	}{
+		{private: privateA, valid: true},
+		{private: privateB, valid: true},
+		{private: privateC, valid: false},
+	} {
+		opener := New()
+		if err := opener.SetIdentity(test.private); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := opener.Open(sealed)
+		if test.valid && err != nil {
+			t.Errorf("Test #%d: Expected to decrypt, but saw: %v", i, err)
+		} else if test.valid && !bytes.Equal(actual, plaintext) {
+			t.Errorf("Test #%d: Expected '%s' but saw '%s'", i, plaintext, actual)
+		} else if !test.valid && err == nil {
+			t.Errorf("Test #%d: Expected decryption to fail", i)
+		}
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	for i, key := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("too short"))} {
+		if err := New().AddRecipient(key); err == nil {
+			t.Errorf("Test #%d: Expected '%s' to be an invalid key", i, key)
+		}
+	}
+	if _, err := New().Seal(plaintext); err == nil {
+		t.Error("Expected sealing without a passphrase or recipient to fail")
+	}
+}
+
+func generateKey(t *testing.T) (string, string) {
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(public[:]),
+		base64.StdEncoding.EncodeToString(private[:])
+}
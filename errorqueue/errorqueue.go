@@ -1,7 +1,9 @@
 package errorqueue
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/golang-collections/go-datastructures/queue"
 )
@@ -12,6 +14,13 @@ type ErrorQueue struct {
 	q *queue.Queue
 }
 
+// MultiError is the error returned by Flush(). It keeps every queued error
+// so callers can inspect them individually, and supports errors.Is() and
+// errors.As() by matching against each of them in turn.
+type MultiError struct {
+	errs []error
+}
+
 // New provisions a new ErrorQueue. It should be disposed of using Flush()
 func New() *ErrorQueue {
 	return &ErrorQueue{q: queue.New(0)}
@@ -28,21 +37,66 @@ func (q *ErrorQueue) Enqueue(items ...error) {
 	}
 }
 
-// Flush coalesces all the errors in the queue into a single error, disposes of
-// the underlying queueing structures and returns the error. If the queue is
-// empty, it returns nil
+// Flush coalesces all the errors in the queue into a single *MultiError,
+// disposes of the underlying queueing structures and returns the error. If
+// the queue is empty, it returns nil
 func (q *ErrorQueue) Flush() error {
 	if q.q.Empty() {
 		q.q.Dispose()
 		return nil
 	}
 	length := q.q.Len()
-	errors, qErr := q.q.Get(length)
+	items, qErr := q.q.Get(length)
 	if qErr != nil {
 		// Get() only returns an error if Dispose has already been called on this
 		// queue.
 		panic(qErr)
 	}
 	q.q.Dispose()
-	return fmt.Errorf("%d error(s) occurred:\n%v", length, errors)
+
+	multiErr := &MultiError{errs: []error{}}
+	for _, item := range items {
+		multiErr.errs = append(multiErr.errs, item.(error))
+	}
+	return multiErr
+}
+
+// Errors returns every error in the MultiError
+func (m *MultiError) Errors() []error {
+	return m.errs
+}
+
+func (m *MultiError) Error() string {
+	msgs := []string{}
+	for _, err := range m.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d error(s) occurred:\n%s", len(m.errs), strings.Join(msgs, "\n"))
+}
+
+// Unwrap returns every error in the MultiError. It is used by errors.Is()
+// and errors.As() from Go 1.20 onwards.
+func (m *MultiError) Unwrap() []error {
+	return m.errs
+}
+
+// Is returns true if any error in the MultiError matches target
+func (m *MultiError) Is(target error) bool {
+	for _, err := range m.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in the MultiError that matches target, and if
+// one is found, sets target to that error value and returns true.
+func (m *MultiError) As(target interface{}) bool {
+	for _, err := range m.errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,48 @@
+package errorqueue
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type typedError struct {
+	name string
+}
+
+func (e *typedError) Error() string {
+	return e.name
+}
+
+var errSentinel = errors.New("sentinel")
+
+func TestFlushEmpty(t *testing.T) {
+	if err := New().Flush(); err != nil {
+		t.Errorf("Expected nil but saw '%v'", err)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	q := New()
+	q.Enqueue(errors.New("first"), fmt.Errorf("wrapped: %w", errSentinel))
+	q.Enqueue(&typedError{name: "typed"})
+	err := q.Flush()
+
+	multiErr, ok := err.(*MultiError)
+	if !ok {
+		t.Fatalf("Expected a *MultiError but saw %T", err)
+	}
+	if len(multiErr.Errors()) != 3 {
+		t.Errorf("Expected 3 errors but saw %d", len(multiErr.Errors()))
+	}
+	if !errors.Is(err, errSentinel) {
+		t.Errorf("Expected errors.Is() to find the wrapped sentinel error")
+	}
+	var typed *typedError
+	if !errors.As(err, &typed) {
+		t.Fatalf("Expected errors.As() to find the typed error")
+	}
+	if typed.name != "typed" {
+		t.Errorf("Expected 'typed' but saw '%s'", typed.name)
+	}
+}
@@ -0,0 +1,172 @@
+// Package journal records the recipes pachelbel has started so that a later
+// run can resume waiting on them if pachelbel exits before they finish.
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Operations that start recipes
+const (
+	OpCreate      = "create"
+	OpResize      = "resize"
+	OpUpgrade     = "upgrade"
+	OpDeprovision = "deprovision"
+	OpWhitelist   = "whitelist"
+	OpBackup      = "backup"
+)
+
+// Entry is a recipe that has been started and has not been seen to finish
+type Entry struct {
+	Deployment   string    `json:"deployment"`
+	DeploymentID string    `json:"deployment_id,omitempty"`
+	Operation    string    `json:"operation"`
+	RecipeID     string    `json:"recipe_id"`
+	Timeout      float64   `json:"timeout"`
+	StartedAt    time.Time `json:"started_at"`
+}
+
+type journalFile struct {
+	Recipes []Entry `json:"recipes"`
+}
+
+// Journal is a threadsafe record of in-flight recipes, keyed by deployment
+// name, that is written to disk every time it changes. A nil *Journal is
+// valid and records nothing.
+type Journal struct {
+	path    string
+	lock    sync.Mutex
+	entries map[string]Entry
+}
+
+// Open reads the journal at path, if it exists, and returns it. If path is
+// empty nil is returned, disabling journaling.
+func Open(path string) (*Journal, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	j := &Journal{path: path, entries: make(map[string]Entry)}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return j, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var contents journalFile
+	if err = json.Unmarshal(data, &contents); err != nil {
+		return nil, fmt.Errorf("Unable to read the state journal '%s':\n%v", path, err)
+	}
+	for _, entry := range contents.Recipes {
+		j.entries[entry.Deployment] = entry
+	}
+	return j, nil
+}
+
+// Start records that a recipe has been started for a deployment
+func (j *Journal) Start(entry Entry) error {
+	if j == nil {
+		return nil
+	}
+	if entry.StartedAt.IsZero() {
+		entry.StartedAt = time.Now().UTC()
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.entries[entry.Deployment] = entry
+	return j.write()
+}
+
+// Finish removes the recipe for a deployment from the journal. It does
+// nothing if the journal holds a different recipe for that deployment.
+func (j *Journal) Finish(deployment, recipeID string) error {
+	if j == nil {
+		return nil
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if entry, ok := j.entries[deployment]; !ok || entry.RecipeID != recipeID {
+		return nil
+	}
+	delete(j.entries, deployment)
+	return j.write()
+}
+
+// Get returns the in-flight recipe for a deployment, if any
+func (j *Journal) Get(deployment string) (Entry, bool) {
+	if j == nil {
+		return Entry{}, false
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	entry, ok := j.entries[deployment]
+	return entry, ok
+}
+
+// Pending returns every in-flight recipe, sorted by deployment name
+func (j *Journal) Pending() []Entry {
+	if j == nil {
+		return []Entry{}
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.sorted()
+}
+
+func (j *Journal) sorted() []Entry {
+	entries := []Entry{}
+	for _, entry := range j.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].Deployment < entries[b].Deployment
+	})
+	return entries
+}
+
+// write atomically replaces the journal on disk. Once nothing is in flight
+// the file is removed. The caller must hold the lock.
+func (j *Journal) write() error {
+	if len(j.entries) == 0 {
+		if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(journalFile{Recipes: j.sorted()}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(j.path), "."+filepath.Base(j.path))
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(append(data, '\n')); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), j.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("Unable to write the state journal '%s':\n%v", j.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package journal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pachelbel-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	j, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Start(Entry{Deployment: "pg-01", Operation: OpResize, RecipeID: "r1", Timeout: 900}); err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Start(Entry{Deployment: "es-01", Operation: OpCreate, RecipeID: "r2", Timeout: 900}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := reopened.Pending()
+	if len(pending) != 2 || pending[0].Deployment != "es-01" || pending[1].RecipeID != "r1" {
+		t.Fatalf("Unexpected pending recipes: %+v", pending)
+	}
+	if pending[0].StartedAt.IsZero() {
+		t.Errorf("Expected the start time to be recorded")
+	}
+
+	if err = reopened.Finish("pg-01", "some-other-recipe"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.Get("pg-01"); !ok {
+		t.Errorf("Expected finishing a different recipe to leave 'pg-01' in the journal")
+	}
+	for _, entry := range pending {
+		if err = reopened.Finish(entry.Deployment, entry.RecipeID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the journal to be removed once empty, but saw: %v", err)
+	}
+}
+
+func TestNilJournal(t *testing.T) {
+	j, err := Open("")
+	if err != nil || j != nil {
+		t.Fatalf("Expected a nil journal, but saw %v, %v", j, err)
+	}
+	if err = j.Start(Entry{Deployment: "pg-01"}); err != nil {
+		t.Error(err)
+	}
+	if _, ok := j.Get("pg-01"); ok {
+		t.Errorf("Expected a nil journal to record nothing")
+	}
+	if err = j.Finish("pg-01", ""); err != nil {
+		t.Error(err)
+	}
+}
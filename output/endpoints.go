@@ -0,0 +1,66 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	urlparser "net/url"
+	"strconv"
+	"strings"
+
+	compose "github.com/benjdewan/gocomposeapi"
+)
+
+// Endpoints returns the direct connection strings of a deployment with the
+// endpoint map applied, in the same way they are written to output files.
+// Passwords are left out.
+func (b *Builder) Endpoints(deployment *compose.Deployment) ([]string, error) {
+	connections, err := b.convertConnections(deployment.Connection.Direct)
+	if err != nil {
+		return []string{}, err
+	}
+
+	endpoints := []string{}
+	for _, cxn := range connections {
+		url := urlparser.URL{
+			Scheme:   cxn.Scheme,
+			Host:     cxn.Host,
+			Path:     cxn.Path,
+			RawQuery: cxn.Query,
+		}
+		if cxn.Port != 0 {
+			url.Host = cxn.Host + ":" + strconv.Itoa(cxn.Port)
+		}
+		if len(cxn.Username) > 0 {
+			url.User = urlparser.User(cxn.Username)
+		}
+		endpoints = append(endpoints, url.String())
+	}
+	return endpoints, nil
+}
+
+// CAFingerprint returns the colon separated SHA-256 fingerprint of a base64
+// encoded, PEM formatted CA certificate, as Compose provides it. It returns
+// an empty string if there is no certificate.
+func CAFingerprint(caBase64 string) (string, error) {
+	if len(caBase64) == 0 {
+		return "", nil
+	}
+	data, err := base64.StdEncoding.DecodeString(caBase64)
+	if err != nil {
+		return "", fmt.Errorf("Unable to decode the CA certificate: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return "", errors.New("Unable to decode the CA certificate: no PEM data found")
+	}
+
+	sum := sha256.Sum256(block.Bytes)
+	hexBytes := make([]string, len(sum))
+	for i, b := range sum {
+		hexBytes[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(hexBytes, ":"), nil
+}
@@ -59,7 +59,7 @@ func (b *Builder) AddFake(id string) error {
 	if err != nil {
 		return err
 	}
-	b.yml = append(b.yml, yml)
+	b.yml[segments[1]] = yml
 	return nil
 }
 
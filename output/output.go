@@ -5,6 +5,7 @@ import (
 	"fmt"
 	urlparser "net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -36,14 +37,16 @@ type connectionYAML struct {
 // Builder is the stateful object used to build pachelbel's output files
 type Builder struct {
 	endpointMap map[string]string
-	yml         [][]byte
+	yml         map[string][]byte
+	removed     map[string]struct{}
 }
 
 // New returns an initialized Builder object
 func New(endpointMap map[string]string) *Builder {
 	return &Builder{
 		endpointMap: endpointMap,
-		yml:         [][]byte{},
+		yml:         make(map[string][]byte),
+		removed:     make(map[string]struct{}),
 	}
 }
 
@@ -51,26 +54,66 @@ func New(endpointMap map[string]string) *Builder {
 // information into Builder's internal representation
 func (b *Builder) Add(deployment *compose.Deployment) error {
 	yamlObject, err := b.convert(deployment)
-	b.yml = append(b.yml, yamlObject)
+	b.yml[deployment.Name] = yamlObject
 	return err
 }
 
-func (b *Builder) Write(file string) error {
-	outBytes := bytes.Join(b.yml, []byte("\n"))
-	handle, err := os.Create(file)
-	if err != nil {
+// Remove drops the named deployment from the output, including any entry
+// for it that would otherwise be kept by Merge()
+func (b *Builder) Remove(name string) {
+	delete(b.yml, name)
+	b.removed[name] = struct{}{}
+}
+
+// Merge reads a previously written output file and keeps every deployment
+// in it that has not been added to or removed from the Builder. It must be
+// called after all calls to Add(), AddFake() and Remove(). If the file does
+// not exist there is nothing to merge and no error is returned.
+func (b *Builder) Merge(dst Destination) error {
+	data, err := dst.read()
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
 		return err
 	}
-	defer func() {
-		if closeErr := handle.Close(); closeErr != nil {
-			// Our fd became invalid, or the underlying
-			// syscall was interrupted
-			panic(closeErr)
+
+	existing := make(map[string]outputYAML)
+	if err = yaml.Unmarshal(data, &existing); err != nil {
+		return fmt.Errorf("Unable to merge with the existing output in '%s':\n%v", dst.File, err)
+	}
+	for name, deployment := range existing {
+		if _, ok := b.yml[name]; ok {
+			continue
+		} else if _, ok := b.removed[name]; ok {
+			continue
+		}
+		yml, err := yaml.Marshal(map[string]outputYAML{name: deployment})
+		if err != nil {
+			return err
 		}
-	}()
+		b.yml[name] = yml
+	}
+	return nil
+}
 
-	_, err = handle.Write(outBytes)
-	return err
+// Write writes the connection information collected so far to the provided
+// destination.
+func (b *Builder) Write(dst Destination) error {
+	return dst.write(bytes.Join(b.sorted(), []byte("\n")))
+}
+
+func (b *Builder) sorted() [][]byte {
+	names := []string{}
+	for name := range b.yml {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	yml := [][]byte{}
+	for _, name := range names {
+		yml = append(yml, b.yml[name])
+	}
+	return yml
 }
 
 func (b *Builder) convert(deployment *compose.Deployment) ([]byte, error) {
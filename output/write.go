@@ -0,0 +1,109 @@
+package output
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/benjdewan/pachelbel/envelope"
+)
+
+// DefaultMode is the file mode used for output files unless overridden.
+// Output files contain plaintext credentials, so they are only readable
+// by their owner by default.
+const DefaultMode os.FileMode = 0600
+
+// Destination describes where and how a Builder writes its output
+type Destination struct {
+	// File is the path to write output to. If it is "-" the output is
+	// written to stdout instead.
+	File string
+
+	// Mode is the file mode the output file is created with
+	Mode os.FileMode
+
+	// Merge keeps deployments already in File that were not provisioned
+	// or deprovisioned during this run
+	Merge bool
+
+	// Envelope, if set, is used to encrypt the output before it is
+	// written, and to decrypt the existing output when merging
+	Envelope *envelope.Envelope
+}
+
+// write writes data to the destination. Files are written atomically: the
+// data is written to a temporary file in the same directory, synced to disk
+// and then renamed into place.
+func (dst Destination) write(data []byte) error {
+	if dst.Envelope != nil {
+		sealed, err := dst.Envelope.Seal(data)
+		if err != nil {
+			return err
+		}
+		data = sealed
+	}
+	if dst.File == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return writeAtomic(dst.File, data, dst.Mode)
+}
+
+func (dst Destination) read() ([]byte, error) {
+	data, err := ioutil.ReadFile(dst.File) // #nosec
+	if err != nil || !envelope.IsSealed(data) {
+		return data, err
+	}
+	if dst.Envelope == nil || !dst.Envelope.CanOpen() {
+		return nil, fmt.Errorf("'%s' is encrypted. A passphrase or private key is required to merge with it", dst.File)
+	}
+	return dst.Envelope.Open(data)
+}
+
+// Decrypt reads encrypted output from src, decrypts it using the provided
+// envelope and writes the plaintext to dst.
+func Decrypt(src string, e *envelope.Envelope, dst Destination) error {
+	data, err := ioutil.ReadFile(src) // #nosec
+	if err != nil {
+		return err
+	}
+	plaintext, err := e.Open(data)
+	if err != nil {
+		return fmt.Errorf("Unable to decrypt '%s':\n%v", src, err)
+	}
+	return dst.write(plaintext)
+}
+
+func writeAtomic(file string, data []byte, mode os.FileMode) error {
+	handle, err := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file))
+	if err != nil {
+		return err
+	}
+	if err = writeAndSync(handle, data, mode); err != nil {
+		// Best effort clean up. The write error is more useful to the caller
+		_ = os.Remove(handle.Name())
+		return err
+	}
+	if err = os.Rename(handle.Name(), file); err != nil {
+		_ = os.Remove(handle.Name())
+		return err
+	}
+	return nil
+}
+
+func writeAndSync(handle *os.File, data []byte, mode os.FileMode) error {
+	if err := handle.Chmod(mode); err != nil {
+		_ = handle.Close()
+		return err
+	}
+	if _, err := handle.Write(data); err != nil {
+		_ = handle.Close()
+		return err
+	}
+	if err := handle.Sync(); err != nil {
+		_ = handle.Close()
+		return err
+	}
+	return handle.Close()
+}
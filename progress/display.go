@@ -0,0 +1,81 @@
+package progress
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// ModeAuto uses ModeTerminal if the output is a terminal, otherwise
+	// ModeBars
+	ModeAuto = "auto"
+	// ModeTerminal redraws one row per runner in place
+	ModeTerminal = "tty"
+	// ModeBars prints a row of progress bars every few seconds
+	ModeBars = "bars"
+	// ModePlain prints one line per runner state change
+	ModePlain = "plain"
+	// ModeJSON prints one JSON object per runner state change
+	ModeJSON = "json"
+)
+
+// Display is the interface for reporting the progress of runners. Runners
+// are identified by the name of the deployment they act on.
+type Display interface {
+	// AddBar registers a runner before Start() is called
+	AddBar(action, name string)
+	// Start begins displaying progress
+	Start()
+	// Started marks a runner as having started work
+	Started(name string)
+	// RecipeID reports the ID of a recipe a runner is waiting on
+	RecipeID(name, recipeID string)
+	// RecipeStatus reports the status of a recipe a runner is waiting on,
+	// and the name of the recipe step currently running, if any
+	RecipeStatus(name, recipeID, status, step string)
+	// Done marks a runner as having finished successfully
+	Done(name string)
+	// Error marks a runner as having failed with the given error
+	Error(name string, err error)
+	// Stop flushes any pending output and stops displaying progress
+	Stop()
+}
+
+// NewDisplay returns the Display for the given mode. Progress bars and the
+// terminal display are written to out, which is normally os.Stdout, but
+// events are always written to stderr so they can be separated from other
+// output.
+func NewDisplay(mode string, out *os.File) (Display, error) {
+	switch mode {
+	case ModeAuto, "":
+		if IsTerminal(out) {
+			return newTerminal(out), nil
+		}
+		return NewDisplay(ModeBars, out)
+	case ModeTerminal:
+		return newTerminal(out), nil
+	case ModeBars:
+		bars := New()
+		bars.Writer = out
+		bars.RefreshRate = 3 * time.Second
+		return bars, nil
+	case ModePlain:
+		return NewEvents(os.Stderr, false), nil
+	case ModeJSON:
+		return NewEvents(os.Stderr, true), nil
+	default:
+		return nil, fmt.Errorf("'%s' is not a valid progress mode. Expected one of '%s', '%s', '%s', '%s' or '%s'",
+			mode, ModeAuto, ModeTerminal, ModeBars, ModePlain, ModeJSON)
+	}
+}
+
+func newTerminal(out *os.File) *Terminal {
+	terminal := NewTerminal()
+	terminal.Writer = out
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		terminal.Width = columns
+	}
+	return terminal
+}
@@ -0,0 +1,150 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	eventQueued       = "queued"
+	eventStarted      = "started"
+	eventRecipe       = "recipe"
+	eventRecipeStatus = "recipe_status"
+	eventDone         = "done"
+	eventFailed       = "failed"
+)
+
+// Event is a single runner state change
+// codebeat:disable[TOO_MANY_IVARS]
+type Event struct {
+	Time       time.Time `json:"time"`
+	Event      string    `json:"event"`
+	Action     string    `json:"action"`
+	Deployment string    `json:"deployment"`
+	RecipeID   string    `json:"recipe_id,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	Step       string    `json:"step,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// codebeat:enable[TOO_MANY_IVARS]
+
+// Events is a Display that writes one line per runner state change, either
+// as plain text or as JSON. Unlike ProgressBars it never repeats itself, so
+// it is well suited to CI logs.
+type Events struct {
+	// Writer is where events are written to
+	Writer io.Writer
+
+	// internal fields
+	json     bool
+	actions  map[string]string
+	statuses map[string]string
+	lock     *sync.Mutex
+}
+
+// NewEvents returns an Events display writing to w. If asJSON is true every
+// event is written as a JSON object, otherwise as a line of plain text.
+func NewEvents(w io.Writer, asJSON bool) *Events {
+	return &Events{
+		Writer:   w,
+		json:     asJSON,
+		actions:  make(map[string]string),
+		statuses: make(map[string]string),
+		lock:     &sync.Mutex{},
+	}
+}
+
+// AddBar registers a runner and reports it as queued
+func (e *Events) AddBar(action, name string) {
+	e.lock.Lock()
+	e.actions[name] = action
+	e.lock.Unlock()
+	e.emit(Event{Event: eventQueued, Deployment: name})
+}
+
+// Start is a no-op. Events are written as they happen
+func (e *Events) Start() {}
+
+// Stop is a no-op. Events are written as they happen
+func (e *Events) Stop() {}
+
+// Started reports that a runner has started
+func (e *Events) Started(name string) {
+	e.emit(Event{Event: eventStarted, Deployment: name})
+}
+
+// RecipeID reports the recipe a runner is waiting on
+func (e *Events) RecipeID(name, recipeID string) {
+	e.emit(Event{Event: eventRecipe, Deployment: name, RecipeID: recipeID})
+}
+
+// RecipeStatus reports the status of a recipe a runner is waiting on. It is
+// safe to call repeatedly, only changes in status are written
+func (e *Events) RecipeStatus(name, recipeID, status, step string) {
+	key := name + "/" + recipeID
+	e.lock.Lock()
+	previous := e.statuses[key]
+	e.statuses[key] = status + "/" + step
+	e.lock.Unlock()
+	if previous == status+"/"+step {
+		return
+	}
+	e.emit(Event{
+		Event:      eventRecipeStatus,
+		Deployment: name,
+		RecipeID:   recipeID,
+		Status:     status,
+		Step:       step,
+	})
+}
+
+// Done reports that a runner finished successfully
+func (e *Events) Done(name string) {
+	e.emit(Event{Event: eventDone, Deployment: name})
+}
+
+// Error reports that a runner failed
+func (e *Events) Error(name string, err error) {
+	event := Event{Event: eventFailed, Deployment: name}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	e.emit(event)
+}
+
+func (e *Events) emit(event Event) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	event.Time = time.Now().UTC()
+	event.Action = e.actions[event.Deployment]
+	if e.json {
+		line, err := json.Marshal(event)
+		if err != nil {
+			panic(err)
+		}
+		fprintln(e.Writer, string(line))
+		return
+	}
+	fprintln(e.Writer, plainEvent(event))
+}
+
+func plainEvent(event Event) string {
+	prefix := fmt.Sprintf("%s %s '%s':", event.Time.Format(time.RFC3339), event.Action, event.Deployment)
+	switch event.Event {
+	case eventRecipe:
+		return fmt.Sprintf("%s waiting on recipe %s", prefix, event.RecipeID)
+	case eventRecipeStatus:
+		if len(event.Step) > 0 {
+			return fmt.Sprintf("%s recipe %s is %s (%s)", prefix, event.RecipeID, event.Status, event.Step)
+		}
+		return fmt.Sprintf("%s recipe %s is %s", prefix, event.RecipeID, event.Status)
+	case eventFailed:
+		return fmt.Sprintf("%s failed: %s", prefix, event.Error)
+	default:
+		return fmt.Sprintf("%s %s", prefix, event.Event)
+	}
+}
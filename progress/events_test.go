@@ -0,0 +1,68 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEventsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEvents(&buf, true)
+	e.AddBar("Creating", "pg-01")
+	e.Start()
+	e.Started("pg-01")
+	e.RecipeID("pg-01", "r-1")
+	e.RecipeStatus("pg-01", "r-1", "running", "provision")
+	e.RecipeStatus("pg-01", "r-1", "running", "provision")
+	e.RecipeStatus("pg-01", "r-1", "running", "configure")
+	e.Error("pg-01", errors.New("boom"))
+	e.Stop()
+
+	expected := []string{eventQueued, eventStarted, eventRecipe, eventRecipeStatus, eventRecipeStatus, eventFailed}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d events but saw %d:\n%s", len(expected), len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Event #%d is not valid JSON: %v", i, err)
+		}
+		if event.Event != expected[i] || event.Action != "Creating" || event.Deployment != "pg-01" {
+			t.Errorf("Event #%d: Expected a '%s' event for Creating 'pg-01' but saw %+v", i, expected[i], event)
+		}
+	}
+	if !strings.Contains(lines[len(lines)-1], `"error":"boom"`) {
+		t.Errorf("Expected the failure event to include the error, but saw %s", lines[len(lines)-1])
+	}
+}
+
+func TestPlainEvent(t *testing.T) {
+	for i, test := range plainEventTests {
+		actual := plainEvent(test.event)
+		if !strings.HasSuffix(actual, test.expected) {
+			t.Errorf("Test #%d: Expected '%s' to end with '%s'", i, actual, test.expected)
+		}
+	}
+}
+
+var plainEventTests = []struct {
+	event    Event
+	expected string
+}{
+	{
+		event:    Event{Event: eventDone, Action: "Resizing", Deployment: "redis"},
+		expected: "Resizing 'redis': done",
+	},
+	{
+		event:    Event{Event: eventRecipeStatus, Action: "Resizing", Deployment: "redis", RecipeID: "r-1", Status: "running", Step: "scale"},
+		expected: "Resizing 'redis': recipe r-1 is running (scale)",
+	},
+	{
+		event:    Event{Event: eventFailed, Action: "Resizing", Deployment: "redis", Error: "boom"},
+		expected: "Resizing 'redis': failed: boom",
+	},
+}
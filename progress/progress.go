@@ -68,7 +68,7 @@ func New() *ProgressBars {
 // re-painting screens doesn't work we cannot dynamically add new progress
 // bars, so this method panics if the ProgressBars is currently running when
 // invoked.
-func (p *ProgressBars) AddBar(action, name string) *ProgressBars {
+func (p *ProgressBars) AddBar(action, name string) {
 	if p.started {
 		panic("Progress bars cannot be added while running")
 	}
@@ -77,7 +77,6 @@ func (p *ProgressBars) AddBar(action, name string) *ProgressBars {
 		name:   name,
 		state:  stateRunning,
 	})
-	return p
 }
 
 // Start prints a state header defining the progress bars and then begins
@@ -101,13 +100,23 @@ func (p *ProgressBars) Start() {
 
 }
 
+// Started is a no-op. Every progress bar is drawn as running from Start()
+func (p *ProgressBars) Started(barName string) {}
+
+// RecipeID is a no-op. Progress bars do not display recipe information
+func (p *ProgressBars) RecipeID(barName, recipeID string) {}
+
+// RecipeStatus is a no-op. Progress bars do not display recipe information
+func (p *ProgressBars) RecipeStatus(barName, recipeID, status, step string) {}
+
 // Done terminates a single progress bar by name in a successful state
 func (p *ProgressBars) Done(barName string) {
 	p.changeState(barName, stateDone)
 }
 
-// Done terminates a single progress bar by name in a failure state
-func (p *ProgressBars) Error(barName string) {
+// Error terminates a single progress bar by name in a failure state. The
+// error itself is reported once every runner has finished.
+func (p *ProgressBars) Error(barName string, err error) {
 	p.changeState(barName, stateFailed)
 }
 
@@ -0,0 +1,211 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var spinner = []string{"|", "/", "-", "\\"}
+
+// Terminal is a Display for interactive terminals. It redraws one row per
+// runner in place, showing the runner's action, deployment name, elapsed
+// time and the status of the recipe it is waiting on, if any.
+// codebeat:disable[TOO_MANY_IVARS]
+type Terminal struct {
+	// Writer is where the display is drawn. It must be a terminal that
+	// supports ANSI escape codes. os.Stdout is used by default
+	Writer io.Writer
+
+	// Width is the maximum width of a row. 80 is used by default
+	Width int
+
+	// RefreshRate controls how often the display is redrawn.
+	// 200 milliseconds is the default
+	RefreshRate time.Duration
+
+	// internal fields
+	rows     []*terminalRow
+	frame    int
+	drawn    int
+	stopChan chan struct{}
+	stopped  *sync.WaitGroup
+	lock     *sync.Mutex
+}
+
+// codebeat:enable[TOO_MANY_IVARS]
+
+type terminalRow struct {
+	action   string
+	name     string
+	state    string
+	started  time.Time
+	finished time.Time
+	recipe   string
+	status   string
+	step     string
+}
+
+// NewTerminal provisions a new Terminal display with default values
+func NewTerminal() *Terminal {
+	return &Terminal{
+		Writer:      os.Stdout,
+		Width:       80,
+		RefreshRate: 200 * time.Millisecond,
+		rows:        [](*terminalRow){},
+		stopChan:    make(chan struct{}),
+		stopped:     &sync.WaitGroup{},
+		lock:        &sync.Mutex{},
+	}
+}
+
+// IsTerminal returns true if f is an interactive terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// AddBar adds a row for a runner to the display
+func (t *Terminal) AddBar(action, name string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.rows = append(t.rows, &terminalRow{
+		action: action,
+		name:   name,
+		state:  stateRunning,
+	})
+}
+
+// Start begins redrawing the display until Stop() is called
+func (t *Terminal) Start() {
+	now := time.Now()
+	t.lock.Lock()
+	for _, row := range t.rows {
+		row.started = now
+	}
+	t.lock.Unlock()
+
+	t.stopped.Add(1)
+	go func() {
+		defer t.stopped.Done()
+		ticker := time.NewTicker(t.RefreshRate)
+		defer ticker.Stop()
+		for {
+			t.draw()
+			select {
+			case <-t.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Started resets the elapsed time of a runner's row
+func (t *Terminal) Started(name string) {
+	t.update(name, func(row *terminalRow) { row.started = time.Now() })
+}
+
+// RecipeID displays the recipe a runner is waiting on
+func (t *Terminal) RecipeID(name, recipeID string) {
+	t.update(name, func(row *terminalRow) {
+		row.recipe = recipeID
+		row.status = "waiting"
+		row.step = ""
+	})
+}
+
+// RecipeStatus displays the status of the recipe a runner is waiting on
+func (t *Terminal) RecipeStatus(name, recipeID, status, step string) {
+	t.update(name, func(row *terminalRow) {
+		row.recipe = recipeID
+		row.status = status
+		row.step = step
+	})
+}
+
+// Done marks a runner's row as finished successfully
+func (t *Terminal) Done(name string) {
+	t.update(name, func(row *terminalRow) {
+		row.state = stateDone
+		row.finished = time.Now()
+	})
+}
+
+// Error marks a runner's row as failed. The error itself is reported once
+// every runner has finished.
+func (t *Terminal) Error(name string, err error) {
+	t.update(name, func(row *terminalRow) {
+		row.state = stateFailed
+		row.finished = time.Now()
+	})
+}
+
+// Stop draws the display one final time and stops redrawing it
+func (t *Terminal) Stop() {
+	close(t.stopChan)
+	t.stopped.Wait()
+	t.draw()
+}
+
+func (t *Terminal) update(name string, fn func(*terminalRow)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, row := range t.rows {
+		if row.name == name {
+			fn(row)
+			return
+		}
+	}
+}
+
+func (t *Terminal) draw() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var buf strings.Builder
+	if t.drawn > 0 {
+		// Move the cursor back to the first row
+		fmt.Fprintf(&buf, "\033[%dA", t.drawn)
+	}
+	for _, row := range t.rows {
+		buf.WriteString("\033[2K")
+		buf.WriteString(truncate(row.render(spinner[t.frame%len(spinner)]), t.Width))
+		buf.WriteString("\n")
+	}
+	t.drawn = len(t.rows)
+	t.frame++
+	fprintf(t.Writer, "%s", buf.String())
+}
+
+func (row *terminalRow) render(spin string) string {
+	symbol, end := spin, time.Now()
+	switch row.state {
+	case stateDone:
+		symbol, end = "✓", row.finished
+	case stateFailed:
+		symbol, end = "✗", row.finished
+	}
+	elapsed := end.Sub(row.started).Truncate(time.Second)
+
+	status := ""
+	if len(row.recipe) > 0 {
+		status = fmt.Sprintf("recipe %s %s", row.recipe, row.status)
+		if len(row.step) > 0 {
+			status = fmt.Sprintf("%s (%s)", status, row.step)
+		}
+	}
+	return strings.TrimRight(fmt.Sprintf("%s %s '%s' %8s %s",
+		symbol, row.action, row.name, elapsed, status), " ")
+}
+
+func truncate(str string, width int) string {
+	runes := []rune(str)
+	if width <= 0 || len(runes) <= width {
+		return str
+	}
+	return string(runes[:width])
+}
@@ -0,0 +1,65 @@
+package progress
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTerminalRowRender(t *testing.T) {
+	started := time.Now()
+	for i, test := range []struct {
+		row      terminalRow
+		expected string
+	}{
+		{
+			row:      terminalRow{action: "Creating", name: "pg", state: stateRunning, started: started},
+			expected: "| Creating 'pg'       0s",
+		},
+		{
+			row: terminalRow{action: "Resizing", name: "redis", state: stateRunning, started: started,
+				recipe: "r-1", status: "running", step: "scale"},
+			expected: "| Resizing 'redis'       0s recipe r-1 running (scale)",
+		},
+		{
+			row: terminalRow{action: "Upgrading", name: "es", state: stateDone,
+				started: started, finished: started.Add(90 * time.Second)},
+			expected: "✓ Upgrading 'es'    1m30s",
+		},
+	} {
+		if actual := test.row.render("|"); actual != test.expected {
+			t.Errorf("Test #%d: Expected '%s' but saw '%s'", i, test.expected, actual)
+		}
+	}
+}
+
+func TestTerminalRedraw(t *testing.T) {
+	var buf bytes.Buffer
+	terminal := NewTerminal()
+	terminal.Writer = &buf
+	terminal.RefreshRate = time.Hour
+	terminal.AddBar("Creating", "pg")
+	terminal.AddBar("Creating", "redis")
+	terminal.Start()
+	terminal.Error("redis", errors.New("boom"))
+	terminal.Stop()
+
+	output := buf.String()
+	if !strings.Contains(output, "\033[2A") {
+		t.Errorf("Expected the display to be redrawn in place, but saw %q", output)
+	}
+	if !strings.Contains(output, "✗ Creating 'redis'") {
+		t.Errorf("Expected 'redis' to be marked as failed, but saw %q", output)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if actual := truncate("✓ Creating", 3); actual != "✓ C" {
+		t.Errorf("Expected '✓ C' but saw '%s'", actual)
+	}
+	if actual := truncate("short", 80); actual != "short" {
+		t.Errorf("Expected 'short' but saw '%s'", actual)
+	}
+}
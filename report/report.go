@@ -0,0 +1,265 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// StatusSucceeded is the status of a runner that completed without error
+	StatusSucceeded = "succeeded"
+	// StatusFailed is the status of a runner that returned an error
+	StatusFailed = "failed"
+)
+
+// Result is the outcome of a single runner
+type Result struct {
+	Deployment string
+	Action     string
+	Status     string
+	Duration   time.Duration
+	RecipeIDs  []string
+	Err        error
+}
+
+// Pending is a change pachelbel found but did not make, such as a version
+// upgrade outside of its maintenance window
+type Pending struct {
+	Deployment  string `json:"deployment"`
+	Description string `json:"description"`
+}
+
+// Report collects the Results of a pachelbel run. It is safe for concurrent
+// use.
+type Report struct {
+	results []Result
+	pending []Pending
+	lock    *sync.Mutex
+}
+
+// New returns an empty Report
+func New() *Report {
+	return &Report{
+		results: []Result{},
+		pending: []Pending{},
+		lock:    &sync.Mutex{},
+	}
+}
+
+// AddPending records a change that was not made
+func (r *Report) AddPending(pending Pending) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.pending = append(r.pending, pending)
+}
+
+// PendingChanges returns every pending change recorded so far, sorted by
+// deployment name
+func (r *Report) PendingChanges() []Pending {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	pending := make([]Pending, len(r.pending))
+	copy(pending, r.pending)
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Deployment < pending[j].Deployment
+	})
+	return pending
+}
+
+// Add records a Result
+func (r *Report) Add(result Result) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.results = append(r.results, result)
+}
+
+// Results returns every Result recorded so far, sorted by deployment name
+func (r *Report) Results() []Result {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	results := make([]Result, len(r.results))
+	copy(results, r.results)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Deployment < results[j].Deployment
+	})
+	return results
+}
+
+// WriteSummary writes a human readable table of every Result to w, followed
+// by any pending changes
+func (r *Report) WriteSummary(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "DEPLOYMENT\tACTION\tRESULT\tDURATION\tRECIPES"); err != nil {
+		return err
+	}
+	for _, result := range r.Results() {
+		recipes := strings.Join(result.RecipeIDs, ",")
+		if len(recipes) == 0 {
+			recipes = "-"
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", result.Deployment,
+			result.Action, result.Status, result.Duration.Truncate(time.Second),
+			recipes); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	pending := r.PendingChanges()
+	if len(pending) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "\nPENDING"); err != nil {
+		return err
+	}
+	for _, p := range pending {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", p.Deployment, p.Description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile writes the report to the provided path. The format is chosen
+// by the file extension: '.xml' for JUnit XML and '.json' for JSON.
+func (r *Report) WriteFile(path string) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		data, err = r.JUnit()
+	case ".json":
+		data, err = r.JSON()
+	default:
+		return fmt.Errorf("Cannot write a report to '%s'. Report files must end in '.xml' (JUnit) or '.json'", path)
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+type jsonResult struct {
+	Deployment string   `json:"deployment"`
+	Action     string   `json:"action"`
+	Status     string   `json:"status"`
+	Duration   float64  `json:"duration_seconds"`
+	RecipeIDs  []string `json:"recipe_ids"`
+	Error      string   `json:"error,omitempty"`
+}
+
+type jsonReport struct {
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Results   []jsonResult `json:"results"`
+	Pending   []Pending    `json:"pending"`
+}
+
+// JSON returns the report as a JSON document
+func (r *Report) JSON() ([]byte, error) {
+	out := jsonReport{Results: []jsonResult{}, Pending: r.PendingChanges()}
+	for _, result := range r.Results() {
+		jr := jsonResult{
+			Deployment: result.Deployment,
+			Action:     result.Action,
+			Status:     result.Status,
+			Duration:   result.Duration.Seconds(),
+			RecipeIDs:  result.RecipeIDs,
+		}
+		if jr.RecipeIDs == nil {
+			jr.RecipeIDs = []string{}
+		}
+		if result.Err != nil {
+			jr.Error = result.Err.Error()
+		}
+		if result.Status == StatusFailed {
+			out.Failed++
+		} else {
+			out.Succeeded++
+		}
+		out.Results = append(out.Results, jr)
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// JUnit returns the report as a JUnit XML document. Every Result is a test
+// case named after its deployment, and the action is used as its class name
+func (r *Report) JUnit() ([]byte, error) {
+	suite := junitTestSuite{Name: "pachelbel", Cases: []junitTestCase{}}
+	var total time.Duration
+	for _, result := range r.Results() {
+		testCase := junitTestCase{
+			ClassName: result.Action,
+			Name:      result.Deployment,
+			Time:      seconds(result.Duration),
+		}
+		if len(result.RecipeIDs) > 0 {
+			testCase.SystemOut = "Recipes: " + strings.Join(result.RecipeIDs, ", ")
+		}
+		if result.Status == StatusFailed {
+			suite.Failures++
+			testCase.Failure = &junitFailure{Message: "failed"}
+			if result.Err != nil {
+				testCase.Failure.Message = firstLine(result.Err.Error())
+				testCase.Failure.Body = result.Err.Error()
+			}
+		}
+		total += result.Duration
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Tests = len(suite.Cases)
+	suite.Time = seconds(total)
+
+	body, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func firstLine(str string) string {
+	return strings.SplitN(strings.TrimSpace(str), "\n", 2)[0]
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport() *Report {
+	r := New()
+	r.Add(Result{
+		Deployment: "redis-01",
+		Action:     "Resizing",
+		Status:     StatusFailed,
+		Duration:   90 * time.Second,
+		RecipeIDs:  []string{"r-2"},
+		Err:        errors.New("Timed out waiting on recipe r-2 to complete\nmore detail"),
+	})
+	r.Add(Result{
+		Deployment: "pg-01",
+		Action:     "Creating",
+		Status:     StatusSucceeded,
+		Duration:   2 * time.Second,
+		RecipeIDs:  []string{"r-1"},
+	})
+	return r
+}
+
+func TestJUnit(t *testing.T) {
+	data, err := testReport().JUnit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("Invalid XML: %v\n%s", err, data)
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != 2 || suite.Failures != 1 {
+		t.Errorf("Expected 2 tests and 1 failure but saw %d and %d", suite.Tests, suite.Failures)
+	}
+	if suite.Cases[0].Name != "pg-01" || suite.Cases[0].Failure != nil {
+		t.Errorf("Expected 'pg-01' to pass, but saw %+v", suite.Cases[0])
+	}
+	failure := suite.Cases[1].Failure
+	if failure == nil || failure.Message != "Timed out waiting on recipe r-2 to complete" {
+		t.Errorf("Expected 'redis-01' to fail with the first line of its error, but saw %+v", failure)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := testReport().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out jsonReport
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+	if out.Succeeded != 1 || out.Failed != 1 || len(out.Results) != 2 {
+		t.Errorf("Unexpected report: %+v", out)
+	}
+	if out.Results[1].Duration != 90 || out.Results[1].RecipeIDs[0] != "r-2" {
+		t.Errorf("Unexpected result: %+v", out.Results[1])
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport().WriteSummary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 rows, but saw:\n%s", buf.String())
+	}
+	if fields := strings.Fields(lines[2]); strings.Join(fields, " ") != "redis-01 Resizing failed 1m30s r-2" {
+		t.Errorf("Unexpected row: '%s'", lines[2])
+	}
+}
+
+func TestPending(t *testing.T) {
+	r := testReport()
+	r.AddPending(Pending{Deployment: "pg-01", Description: "Upgrade to 9.6.5 is pending: the upgrade_policy is report_only"})
+
+	var buf bytes.Buffer
+	if err := r.WriteSummary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "\nPENDING\npg-01: Upgrade to 9.6.5 is pending: the upgrade_policy is report_only\n") {
+		t.Errorf("Expected the summary to end with the pending upgrade, but saw:\n%s", buf.String())
+	}
+
+	data, err := r.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out jsonReport
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+	if len(out.Pending) != 1 || out.Pending[0].Deployment != "pg-01" {
+		t.Errorf("Unexpected pending changes: %+v", out.Pending)
+	}
+}
+
+func TestWriteFileExtension(t *testing.T) {
+	if err := New().WriteFile("report.txt"); err == nil {
+		t.Error("Expected an unsupported report extension to fail")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/benjdewan/pachelbel/connection"
 	"github.com/benjdewan/pachelbel/errorqueue"
 	progressbars "github.com/benjdewan/pachelbel/progress"
+	"github.com/benjdewan/pachelbel/report"
 )
 
 // Accessor is the interface for any Compose Deployment information request.
@@ -23,18 +24,22 @@ type Accessor interface {
 // work with Compose
 type Controller struct {
 	cxn      *connection.Connection
-	progress *progressbars.ProgressBars
+	progress progressbars.Display
+	report   *report.Report
+	recipes  *sync.Map
 	dryRun   bool
 }
 
-// NewController creates a new Controller object
-func NewController(cxn *connection.Connection, dryRun bool) *Controller {
+// NewController creates a new Controller object that reports the progress
+// of its runners to the provided display
+func NewController(cxn *connection.Connection, display progressbars.Display, dryRun bool) *Controller {
 	ctl := &Controller{
-		cxn:      cxn,
-		progress: progressbars.New(),
+		progress: display,
+		report:   report.New(),
+		recipes:  &sync.Map{},
 		dryRun:   dryRun,
 	}
-	ctl.progress.RefreshRate = 3 * time.Second
+	ctl.cxn = cxn.WithObserver(ctl)
 	return ctl
 }
 
@@ -50,8 +55,13 @@ func (ctl *Controller) Run(runners []Runner) error {
 
 	for _, runner := range runners {
 		go func(r Runner) {
-			if err := r.Run(ctl.cxn, r.Target); err != nil {
-				ctl.progress.Error(r.Target.GetName())
+			cxn := ctl.cxn.ForRunner(r.Action, r.Target.GetName())
+			ctl.progress.Started(r.Target.GetName())
+			start := time.Now()
+			err := r.Run(cxn, r.Target)
+			ctl.record(r, time.Since(start), err)
+			if err != nil {
+				ctl.progress.Error(r.Target.GetName(), err)
 				q.Enqueue(err)
 			} else {
 				ctl.progress.Done(r.Target.GetName())
@@ -64,6 +74,41 @@ func (ctl *Controller) Run(runners []Runner) error {
 	return q.Flush()
 }
 
+// Report returns the results of every Runner processed by Run()
+func (ctl *Controller) Report() *report.Report {
+	return ctl.report
+}
+
+// RecipeID records the recipes each runner waits on for the report, and
+// forwards them to the progress display
+func (ctl *Controller) RecipeID(name, recipeID string) {
+	ids, _ := ctl.recipes.LoadOrStore(name, &[]string{})
+	*ids.(*[]string) = append(*ids.(*[]string), recipeID)
+	ctl.progress.RecipeID(name, recipeID)
+}
+
+// RecipeStatus forwards recipe status updates to the progress display
+func (ctl *Controller) RecipeStatus(name, recipeID, status, step string) {
+	ctl.progress.RecipeStatus(name, recipeID, status, step)
+}
+
+func (ctl *Controller) record(r Runner, duration time.Duration, err error) {
+	result := report.Result{
+		Deployment: r.Target.GetName(),
+		Action:     r.Action,
+		Status:     report.StatusSucceeded,
+		Duration:   duration,
+		Err:        err,
+	}
+	if err != nil {
+		result.Status = report.StatusFailed
+	}
+	if ids, ok := ctl.recipes.Load(result.Deployment); ok {
+		result.RecipeIDs = *ids.(*[]string)
+	}
+	ctl.report.Add(result)
+}
+
 func (ctl *Controller) register(runners []Runner) []Runner {
 	for i := range runners {
 		if ctl.dryRun {
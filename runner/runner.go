@@ -19,8 +19,20 @@ const (
 	ActionUpgrade = "Upgrading"
 	// ActionComment indicates we are updating the notes on a deployment
 	ActionComment = "Commenting on"
+	// ActionWhitelist indicates we are updating the whitelist of a deployment
+	ActionWhitelist = "Whitelisting"
+	// ActionBackup indicates we are taking an on-demand backup of a deployment
+	ActionBackup = "Backing up"
+	// ActionWait indicates we are waiting on a recipe started elsewhere
+	ActionWait = "Waiting on"
 	// ActionDeprovision indicates we are deprovisioning a deployment
 	ActionDeprovision = "Deprovisioning"
+	// ActionScheduleDeprovision indicates we are backing up a deployment and
+	// marking it to be deprovisioned once a grace period expires
+	ActionScheduleDeprovision = "Scheduling deprovisioning of"
+	// ActionReap indicates we are deprovisioning a deployment whose grace
+	// period has expired
+	ActionReap = "Reaping"
 )
 
 // RunFunc is the signature of actions a Runner object can take like
@@ -39,6 +51,28 @@ func Deprovision(cxn *connection.Connection, accessor Accessor) error {
 	return cxn.Deprovision(accessor.(connection.Deprovision))
 }
 
+// ScheduleDeprovision is the RunFunc for marking a deployment to be
+// deprovisioned once a grace period expires
+func ScheduleDeprovision(cxn *connection.Connection, accessor Accessor) error {
+	return cxn.ScheduleDeprovision(accessor.(connection.GraceDeprovision))
+}
+
+// Reap is the RunFunc for deprovisioning a deployment whose grace period
+// has expired
+func Reap(cxn *connection.Connection, accessor Accessor) error {
+	return cxn.Reap(accessor.(connection.Deprovision))
+}
+
+// Backup is the RunFunc for taking an on-demand backup of a deployment
+func Backup(cxn *connection.Connection, accessor Accessor) error {
+	return cxn.Backup(accessor.(connection.Backup))
+}
+
+// WaitRecipe is the RunFunc for waiting on a recipe started elsewhere
+func WaitRecipe(cxn *connection.Connection, accessor Accessor) error {
+	return cxn.WaitRecipe(accessor.(connection.RecipeWait))
+}
+
 // Update is the RunFunc for updating a deployment if there is anything
 // that can be updated
 func Update(cxn *connection.Connection, accessor Accessor) error {
@@ -48,6 +82,10 @@ func Update(cxn *connection.Connection, accessor Accessor) error {
 		return err
 	}
 
+	if err := cxn.BackupBeforeUpgrade(deployment); err != nil {
+		return err
+	}
+
 	if err := cxn.UpdateVersion(deployment); err != nil {
 		return err
 	}
@@ -56,6 +94,10 @@ func Update(cxn *connection.Connection, accessor Accessor) error {
 		return err
 	}
 
+	if err := cxn.UpdateWhitelist(deployment.GetID(), deployment); err != nil {
+		return err
+	}
+
 	if err := cxn.AddTeams(deployment.GetID(), deployment); err != nil {
 		return err
 	}
@@ -75,6 +117,10 @@ func Create(cxn *connection.Connection, accessor Accessor) error {
 	if err := cxn.AddTeams(newDeployment.ID, deployment); err != nil {
 		return err
 	}
+
+	if err := cxn.UpdateWhitelist(newDeployment.ID, deployment); err != nil {
+		return err
+	}
 	cxn.Add(newDeployment.ID)
 	return nil
 }
@@ -102,6 +148,19 @@ func dryRunUpdate(cxn *connection.Connection, accessor Accessor) error {
 }
 
 func dryRunDeprovision(cxn *connection.Connection, accessor Accessor) error {
+	cxn.AddDeprovisioned(accessor.GetName())
+	return nil
+}
+
+func dryRunBackup(cxn *connection.Connection, accessor Accessor) error {
+	return nil
+}
+
+func dryRunScheduleDeprovision(cxn *connection.Connection, accessor Accessor) error {
+	return nil
+}
+
+func dryRunWait(cxn *connection.Connection, accessor Accessor) error {
 	return nil
 }
 
@@ -111,10 +170,16 @@ func toDryRun(action string) RunFunc {
 		return dryRunLookup
 	case ActionCreate:
 		return dryRunCreate
-	case ActionDeprovision:
+	case ActionDeprovision, ActionReap:
 		return dryRunDeprovision
+	case ActionScheduleDeprovision:
+		return dryRunScheduleDeprovision
+	case ActionBackup:
+		return dryRunBackup
+	case ActionWait:
+		return dryRunWait
 	default:
-		if strings.Contains(action, ActionUpgrade) || strings.Contains(action, ActionResize) || strings.Contains(action, ActionComment) {
+		if strings.Contains(action, ActionUpgrade) || strings.Contains(action, ActionResize) || strings.Contains(action, ActionComment) || strings.Contains(action, ActionWhitelist) {
 			return dryRunUpdate
 		}
 		log.Panicf("Unknown action: %s", action)